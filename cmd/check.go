@@ -2,24 +2,44 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/alajmo/sake/core"
+	"github.com/alajmo/sake/core/dao"
 )
 
-func checkCmd(configErr *error) *cobra.Command {
+func checkCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var strict bool
+
 	cmd := cobra.Command{
 		Use:   "check",
 		Short: "Validate config",
-		Long:  `Validate config.`,
+		Long: `Validate config.
+
+With --strict, fields that are not part of the config schema, such as misspelled fields, are reported as errors.`,
 		Example: `  # Validate config
-  sake check`,
+  sake check
+
+  # Validate config and report unknown fields
+  sake check --strict`,
 		Args: cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			if *configErr != nil {
+			var strictErr error
+			if strict && config.Path != "" {
+				strictErr = dao.CheckUnknownFields(config.Path)
+			}
+
+			if *configErr != nil || strictErr != nil {
 				fmt.Printf("Found configuration errors:\n\n")
-				core.Exit(*configErr)
+				if strictErr != nil {
+					fmt.Fprint(os.Stderr, strictErr)
+				}
+				if *configErr != nil {
+					core.Exit(*configErr)
+				}
+				os.Exit(1)
 			}
 
 			fmt.Println("Config Valid")
@@ -27,5 +47,7 @@ func checkCmd(configErr *error) *cobra.Command {
 		DisableAutoGenTag: true,
 	}
 
+	cmd.Flags().BoolVar(&strict, "strict", false, "report unknown fields")
+
 	return &cmd
 }
@@ -24,6 +24,7 @@ func describeCmd(config *dao.Config, configErr *error) *cobra.Command {
 		describeServersCmd(config, configErr),
 		describeTasksCmd(config, configErr),
 		describeTargetsCmd(config, configErr),
+		describeServerGroupsCmd(config, configErr),
 		describeSpecsCmd(config, configErr),
 	)
 
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/sake/core"
+	"github.com/alajmo/sake/core/dao"
+	"github.com/alajmo/sake/core/print"
+)
+
+func describeServerGroupsCmd(config *dao.Config, configErr *error) *cobra.Command {
+	cmd := cobra.Command{
+		Aliases: []string{"server-group", "groups", "group"},
+		Use:     "server-groups [server-groups]",
+		Short:   "Describe server groups",
+		Long:    "Describe server groups.",
+		Example: `  # Describe all server groups
+  sake describe server-groups`,
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+			describeServerGroups(config, args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if *configErr != nil {
+				return []string{}, cobra.ShellCompDirectiveDefault
+			}
+
+			groups := config.GetServerGroupNames()
+			return groups, cobra.ShellCompDirectiveNoFileComp
+		},
+		DisableAutoGenTag: true,
+	}
+	cmd.Flags().SortFlags = false
+
+	return &cmd
+}
+
+func describeServerGroups(config *dao.Config, args []string) {
+	groups, err := config.GetServerGroupsByName(args)
+	core.CheckIfError(err)
+
+	print.PrintServerGroupBlocks(groups)
+}
@@ -39,7 +39,7 @@ func describeServersCmd(config *dao.Config, configErr *error) *cobra.Command {
 	}
 	cmd.Flags().SortFlags = false
 
-	cmd.Flags().StringSliceVarP(&serverFlags.Tags, "tags", "t", []string{}, "filter servers by their tag")
+	cmd.Flags().StringSliceVarP(&serverFlags.Tags, "tags", "t", []string{}, "filter servers by their tags or tag expressions")
 	err := cmd.RegisterFlagCompletionFunc("tags", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if *configErr != nil {
 			return []string{}, cobra.ShellCompDirectiveDefault
@@ -51,6 +51,7 @@ func describeServersCmd(config *dao.Config, configErr *error) *cobra.Command {
 	core.CheckIfError(err)
 
 	cmd.Flags().StringVarP(&serverFlags.Regex, "regex", "r", "", "filter servers on host regex")
+	cmd.Flags().StringArrayVar(&serverFlags.Where, "where", []string{}, "filter servers by field or env, for instance user=deploy, port!=22, env.REGION=eu-* or bastion~=jump1")
 	cmd.Flags().BoolVarP(&serverFlags.Invert, "invert", "v", false, "invert matching on servers")
 	cmd.Flags().BoolVarP(&serverFlags.Edit, "edit", "e", false, "edit server")
 
@@ -91,7 +92,7 @@ func describeServers(
 		err := config.ParseInventory(userArgs)
 		core.CheckIfError(err)
 
-		servers, err := config.FilterServers(allServers, serverArgs, serverFlags.Tags, serverFlags.Regex, serverFlags.Invert)
+		servers, err := config.FilterServers(allServers, serverArgs, serverFlags.Tags, serverFlags.Regex, serverFlags.Where, serverFlags.Invert)
 		core.CheckIfError(err)
 
 		if len(servers) > 0 {
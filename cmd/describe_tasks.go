@@ -59,7 +59,7 @@ func describe(config *dao.Config, args []string, taskFlags core.TaskFlags) {
 		if len(tasks) > 0 {
 			for i := range tasks {
 				for j := range tasks[i].Tasks {
-					envs, err := dao.ParseTaskEnv(tasks[i].Tasks[j].Envs, []string{}, []string{}, []string{})
+					envs, err := dao.ParseTaskEnv(tasks[i].Tasks[j].Envs, []string{}, []string{}, []string{}, tasks[i].SecretEnvs)
 					core.CheckIfError(err)
 
 					tasks[i].Tasks[j].Envs = envs
@@ -28,7 +28,10 @@ before the command gets executed in each directory.`,
   sake exec --all ls
 
   # List git files that have markdown suffix for all servers
-  sake exec --all 'git ls-files | grep -e ".md"'`,
+  sake exec --all 'git ls-files | grep -e ".md"'
+
+  # Send piped input to stdin of the command on all servers
+  cat script.sql | sake exec --all --stdin - 'psql mydb'`,
 		Args: cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			core.CheckIfError(*configErr)
@@ -52,12 +55,15 @@ before the command gets executed in each directory.`,
 			setRunFlags.OmitEmptyRows = cmd.Flags().Changed("omit-empty-rows")
 			setRunFlags.OmitEmptyColumns = cmd.Flags().Changed("omit-empty-columns")
 			setRunFlags.Regex = cmd.Flags().Changed("regex")
+			setRunFlags.Where = cmd.Flags().Changed("where")
 			setRunFlags.Report = cmd.Flags().Changed("report")
 			setRunFlags.Servers = cmd.Flags().Changed("servers")
 			setRunFlags.Silent = cmd.Flags().Changed("silent")
 			setRunFlags.Confirm = cmd.Flags().Changed("confirm")
 			setRunFlags.Step = cmd.Flags().Changed("step")
 			setRunFlags.TTY = cmd.Flags().Changed("tty")
+			setRunFlags.PTY = cmd.Flags().Changed("pty")
+			setRunFlags.Stdin = cmd.Flags().Changed("stdin")
 			setRunFlags.Tags = cmd.Flags().Changed("tags")
 			setRunFlags.Verbose = cmd.Flags().Changed("verbose")
 			setRunFlags.MaxFailPercentage = cmd.Flags().Changed("max-fail-percentage")
@@ -147,6 +153,7 @@ before the command gets executed in each directory.`,
 	cmd.Flags().BoolVarP(&runFlags.All, "all", "a", false, "target all servers")
 	cmd.Flags().BoolVarP(&runFlags.Invert, "invert", "v", false, "invert matching on servers")
 	cmd.Flags().StringVarP(&runFlags.Regex, "regex", "r", "", "filter servers on host regex")
+	cmd.Flags().StringArrayVar(&runFlags.Where, "where", []string{}, "target servers by field or env, for instance user=deploy, port!=22, env.REGION=eu-* or bastion~=jump1")
 
 	cmd.Flags().StringSliceVarP(&runFlags.Servers, "servers", "s", []string{}, "target servers by names")
 	err = cmd.RegisterFlagCompletionFunc("servers", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -158,7 +165,7 @@ before the command gets executed in each directory.`,
 	})
 	core.CheckIfError(err)
 
-	cmd.Flags().StringSliceVarP(&runFlags.Tags, "tags", "t", []string{}, "target servers by tags")
+	cmd.Flags().StringSliceVarP(&runFlags.Tags, "tags", "t", []string{}, "target servers by tags or tag expressions")
 	err = cmd.RegisterFlagCompletionFunc("tags", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if *configErr != nil {
 			return []string{}, cobra.ShellCompDirectiveDefault
@@ -168,7 +175,7 @@ before the command gets executed in each directory.`,
 	})
 	core.CheckIfError(err)
 
-	cmd.Flags().StringVarP(&runFlags.Target, "target", "T", "", "target servers by target name")
+	cmd.Flags().StringSliceVarP(&runFlags.Target, "target", "T", []string{}, "target servers by target names, multiple targets are combined")
 	err = cmd.RegisterFlagCompletionFunc("target", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if *configErr != nil {
 			return []string{}, cobra.ShellCompDirectiveDefault
@@ -243,9 +250,13 @@ before the command gets executed in each directory.`,
 	core.CheckIfError(err)
 
 	cmd.Flags().BoolVar(&runFlags.TTY, "tty", false, "replace the current process")
+	cmd.Flags().BoolVar(&runFlags.PTY, "pty", false, "allocate a pseudo-terminal on the remote host")
 	cmd.Flags().BoolVar(&runFlags.Attach, "attach", false, "ssh to server after command")
 	cmd.Flags().BoolVar(&runFlags.Local, "local", false, "run command on localhost")
+	cmd.Flags().StringVar(&runFlags.Stdin, "stdin", "", "send file content to stdin of each command, use - to read from stdin")
 	cmd.MarkFlagsMutuallyExclusive("tty", "attach", "local")
+	cmd.MarkFlagsMutuallyExclusive("tty", "pty")
+	cmd.MarkFlagsMutuallyExclusive("stdin", "tty", "pty")
 
 	cmd.Flags().StringSliceVarP(&runFlags.Report, "report", "R", []string{"recap"}, "reports to show")
 	err = cmd.RegisterFlagCompletionFunc("report", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -273,8 +284,20 @@ func execTask(
 	err := config.ParseInventory([]string{})
 	core.CheckIfError(err)
 
-	servers, err := config.FilterServers(runFlags.All, runFlags.Servers, runFlags.Tags, runFlags.Regex, runFlags.Invert)
-	core.CheckIfError(err)
+	var servers []dao.Server
+	if len(runFlags.Target) > 0 && len(runFlags.Servers) == 0 && len(runFlags.Tags) == 0 && runFlags.Regex == "" && len(runFlags.Where) == 0 && !runFlags.All {
+		target, err := config.GetTargetsUnion(runFlags.Target)
+		core.CheckIfError(err)
+		invert := target.Invert
+		if setRunFlags.Invert {
+			invert = runFlags.Invert
+		}
+		servers, err = config.FilterTarget(*target, invert)
+		core.CheckIfError(err)
+	} else {
+		servers, err = config.FilterServers(runFlags.All, runFlags.Servers, runFlags.Tags, runFlags.Regex, runFlags.Where, runFlags.Invert)
+		core.CheckIfError(err)
+	}
 
 	if len(servers) == 0 {
 		fmt.Println("No targets")
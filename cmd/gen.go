@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"github.com/alajmo/sake/core"
+	"github.com/alajmo/sake/core/dao"
 )
 
 func genCmd() *cobra.Command {
@@ -27,5 +31,28 @@ func genCmd() *cobra.Command {
 	})
 	core.CheckIfError(err)
 
+	cmd.AddCommand(genSchemaCmd())
+
+	return &cmd
+}
+
+func genSchemaCmd() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "schema",
+		Short: "Generate JSON Schema of the config",
+		Long: `Generate JSON Schema of the config.
+
+Editors that support JSON Schema for YAML files can use it to validate and complete sake.yaml files.`,
+		Example: `  # Save schema to file
+  sake gen schema > sake.schema.json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			schema, err := json.MarshalIndent(dao.Schema(), "", "  ")
+			core.CheckIfError(err)
+			fmt.Println(string(schema))
+		},
+		DisableAutoGenTag: true,
+	}
+
 	return &cmd
 }
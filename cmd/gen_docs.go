@@ -22,7 +22,8 @@ func genDocsCmd(longAppDesc string) *cobra.Command {
 				version,
 				date,
 				rootCmd,
-				checkCmd(&configErr),
+				checkCmd(&config, &configErr),
+				lintCmd(&config, &configErr),
 				runCmd(&config, &configErr),
 				execCmd(&config, &configErr),
 				initCmd(),
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/sake/core"
+	"github.com/alajmo/sake/core/dao"
+)
+
+func lintCmd(config *dao.Config, configErr *error) *cobra.Command {
+	var output string
+
+	cmd := cobra.Command{
+		Use:   "lint",
+		Short: "Find semantic problems in config",
+		Long: `Find semantic problems in config, such as tasks whose target matches no servers,
+registered variables shadowed by env variables, unused specs, targets and themes, tags no server has,
+work_dir on local sub-tasks, tty tasks targeting multiple servers and bastion loops.
+
+Exits with status 1 if any warnings are found.`,
+		Example: `  # Lint config
+  sake lint
+
+  # Print issues as JSON
+  sake lint --output json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+
+			issues := config.Lint()
+
+			switch output {
+			case "json":
+				out, err := json.MarshalIndent(issues, "", "  ")
+				core.CheckIfError(err)
+				fmt.Println(string(out))
+			case "text":
+				for _, issue := range issues {
+					level := text.FgBlue.Sprint(issue.Level)
+					if issue.Level == dao.LintWarning {
+						level = text.FgYellow.Sprint(issue.Level)
+					}
+					fmt.Printf("%s:%d: %s: %s [%s]\n", issue.File, issue.Line, level, issue.Message, issue.Rule)
+				}
+			default:
+				core.Exit(fmt.Errorf("invalid output `%s`, expected one of: text, json", output))
+			}
+
+			for _, issue := range issues {
+				if issue.Level == dao.LintWarning {
+					os.Exit(1)
+				}
+			}
+		},
+		DisableAutoGenTag: true,
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "set output [text|json]")
+	err := cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"text", "json"}, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	return &cmd
+}
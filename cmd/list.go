@@ -33,6 +33,7 @@ func listCmd(config *dao.Config, configErr *error) *cobra.Command {
 		listTasksCmd(config, configErr, &listFlags),
 		listTagsCmd(config, configErr, &listFlags),
 		listTargetsCmd(config, configErr, &listFlags),
+		listServerGroupsCmd(config, configErr, &listFlags),
 		listSpecsCmd(config, configErr, &listFlags),
 	)
 
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/sake/core"
+	"github.com/alajmo/sake/core/dao"
+	"github.com/alajmo/sake/core/print"
+)
+
+var serverGroupHeaders = []string{"server_group", "desc", "extends", "user", "port", "bastion", "tags", "servers"}
+
+func listServerGroupsCmd(config *dao.Config, configErr *error, listFlags *core.ListFlags) *cobra.Command {
+	var serverGroupFlags core.ServerGroupFlags
+
+	cmd := cobra.Command{
+		Aliases: []string{"server-group", "groups", "group"},
+		Use:     "server-groups [server-groups]",
+		Short:   "List server groups",
+		Long:    "List server groups.",
+		Example: `  # List all server groups
+  sake list server-groups`,
+		Run: func(cmd *cobra.Command, args []string) {
+			core.CheckIfError(*configErr)
+			listServerGroups(config, args, listFlags, &serverGroupFlags)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if *configErr != nil {
+				return []string{}, cobra.ShellCompDirectiveDefault
+			}
+
+			groups := config.GetServerGroupNames()
+			return groups, cobra.ShellCompDirectiveNoFileComp
+		},
+		DisableAutoGenTag: true,
+	}
+	cmd.Flags().SortFlags = false
+
+	cmd.Flags().StringSliceVar(&serverGroupFlags.Headers, "headers", serverGroupHeaders, "set headers")
+	err := cmd.RegisterFlagCompletionFunc("headers", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if *configErr != nil {
+			return []string{}, cobra.ShellCompDirectiveDefault
+		}
+
+		validHeaders := serverGroupHeaders
+		return validHeaders, cobra.ShellCompDirectiveDefault
+	})
+	core.CheckIfError(err)
+
+	return &cmd
+}
+
+func listServerGroups(
+	config *dao.Config,
+	args []string,
+	listFlags *core.ListFlags,
+	serverGroupFlags *core.ServerGroupFlags,
+) {
+	theme, err := config.GetTheme(listFlags.Theme)
+	core.CheckIfError(err)
+
+	options := print.PrintTableOptions{
+		Output:           listFlags.Output,
+		Theme:            *theme,
+		OmitEmptyRows:    false,
+		OmitEmptyColumns: true,
+		Resource:         "server_group",
+	}
+
+	groups, err := config.GetServerGroupsByName(args)
+	core.CheckIfError(err)
+
+	if len(groups) > 0 {
+		rows := dao.GetTableData(groups, serverGroupFlags.Headers)
+		err := print.PrintTable(rows, options, serverGroupFlags.Headers, []string{}, true, true)
+		core.CheckIfError(err)
+	}
+}
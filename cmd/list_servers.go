@@ -10,7 +10,7 @@ import (
 	"github.com/alajmo/sake/core/print"
 )
 
-var serverHeaders = []string{"server", "desc", "host", "bastion", "user", "port", "local", "shell", "work_dir", "tags", "identity_file"}
+var serverHeaders = []string{"server", "desc", "host", "bastion", "user", "port", "local", "connection", "shell", "work_dir", "tags", "identity_file"}
 
 func listServersCmd(config *dao.Config, configErr *error, listFlags *core.ListFlags) *cobra.Command {
 	var serverFlags core.ServerFlags
@@ -46,7 +46,8 @@ func listServersCmd(config *dao.Config, configErr *error, listFlags *core.ListFl
 
 	cmd.Flags().BoolVarP(&serverFlags.Invert, "invert", "v", false, "invert matching on servers")
 	cmd.Flags().StringVarP(&serverFlags.Regex, "regex", "r", "", "filter servers on host regex")
-	cmd.Flags().StringSliceVarP(&serverFlags.Tags, "tags", "t", []string{}, "filter servers by tags")
+	cmd.Flags().StringArrayVar(&serverFlags.Where, "where", []string{}, "filter servers by field or env, for instance user=deploy, port!=22, env.REGION=eu-* or bastion~=jump1")
+	cmd.Flags().StringSliceVarP(&serverFlags.Tags, "tags", "t", []string{}, "filter servers by tags or tag expressions")
 	err := cmd.RegisterFlagCompletionFunc("tags", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if *configErr != nil {
 			return []string{}, cobra.ShellCompDirectiveDefault
@@ -93,7 +94,7 @@ func listServers(config *dao.Config, args []string, listFlags *core.ListFlags, s
 	err = config.ParseInventory(userArgs)
 	core.CheckIfError(err)
 
-	servers, err := config.FilterServers(allServers, serverArgs, serverFlags.Tags, serverFlags.Regex, serverFlags.Invert)
+	servers, err := config.FilterServers(allServers, serverArgs, serverFlags.Tags, serverFlags.Regex, serverFlags.Where, serverFlags.Invert)
 	core.CheckIfError(err)
 
 	if len(servers) > 0 {
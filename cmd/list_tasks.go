@@ -8,7 +8,7 @@ import (
 	"github.com/alajmo/sake/core/print"
 )
 
-var taskHeaders = []string{"task", "desc", "local", "tty", "attach", "work_dir", "shell", "spec", "target", "theme"}
+var taskHeaders = []string{"task", "desc", "local", "tty", "pty", "attach", "work_dir", "shell", "spec", "target", "theme"}
 
 func listTasksCmd(config *dao.Config, configErr *error, listFlags *core.ListFlags) *cobra.Command {
 	var taskFlags core.TaskFlags
@@ -83,8 +83,20 @@ func listTasks(
 			headers = taskFlags.Headers
 		}
 
-		rows := dao.GetTableData(tasks, headers)
-		err := print.PrintTable(rows, options, headers, []string{}, true, true)
-		core.CheckIfError(err)
+		// Tasks from namespaced imports are listed in a table per namespace
+		groups := dao.GroupTasksByNamespace(tasks)
+		if len(groups) == 1 || options.Output == "json" || options.Output == "csv" {
+			rows := dao.GetTableData(tasks, headers)
+			err := print.PrintTable(rows, options, headers, []string{}, true, true)
+			core.CheckIfError(err)
+			return
+		}
+
+		for i, group := range groups {
+			options.Title = group.Namespace
+			rows := dao.GetTableData(group.Tasks, headers)
+			err := print.PrintTable(rows, options, headers, []string{}, i == 0, true)
+			core.CheckIfError(err)
+		}
 	}
 }
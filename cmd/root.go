@@ -26,6 +26,7 @@ var (
 	userConfigPath string
 	sshConfigPath  string
 	noColor        bool
+	refreshInv     bool
 	buildMode      = ""
 	version        = "dev"
 	commit         = "none"
@@ -65,6 +66,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&userConfigPath, "user-config", "u", "", "specify user config")
 	rootCmd.PersistentFlags().StringVar(&sshConfigPath, "ssh-config", "", "specify ssh config")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable color")
+	rootCmd.PersistentFlags().BoolVar(&refreshInv, "refresh-inventory", false, "run inventory commands instead of using cached output")
 
 	rootCmd.AddCommand(
 		initCmd(),
@@ -74,9 +76,11 @@ func init() {
 		execCmd(&config, &configErr),
 		sshCmd(&config, &configErr),
 		editCmd(&config, &configErr),
-		checkCmd(&configErr),
+		checkCmd(&config, &configErr),
+		lintCmd(&config, &configErr),
 		completionCmd(),
 		genCmd(),
+		vaultCmd(),
 	)
 
 	rootCmd.SetVersionTemplate(fmt.Sprintf("Version: %-10s\nCommit: %-10s\nDate: %-10s\n", version, commit, date))
@@ -88,4 +92,5 @@ func init() {
 
 func initConfig() {
 	config, configErr = dao.ReadConfig(configPath, userConfigPath, sshConfigPath, noColor)
+	config.RefreshInventory = refreshInv
 }
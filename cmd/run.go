@@ -48,7 +48,10 @@ func runCmd(config *dao.Config, configErr *error) *cobra.Command {
   sake run <task> --servers <server>
 
   # Run task <task> for all servers that have tags <tag>
-  sake run <task> --tags <tag>`,
+  sake run <task> --tags <tag>
+
+  # Send the content of a file to stdin of task <task> on all servers
+  sake run <task> --all --stdin script.sql`,
 		Args: cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			core.CheckIfError(*configErr)
@@ -73,12 +76,15 @@ func runCmd(config *dao.Config, configErr *error) *cobra.Command {
 			setRunFlags.OmitEmptyRows = cmd.Flags().Changed("omit-empty-rows")
 			setRunFlags.OmitEmptyColumns = cmd.Flags().Changed("omit-empty-columns")
 			setRunFlags.Regex = cmd.Flags().Changed("regex")
+			setRunFlags.Where = cmd.Flags().Changed("where")
 			setRunFlags.Report = cmd.Flags().Changed("report")
 			setRunFlags.Servers = cmd.Flags().Changed("servers")
 			setRunFlags.Silent = cmd.Flags().Changed("silent")
 			setRunFlags.Confirm = cmd.Flags().Changed("confirm")
 			setRunFlags.Step = cmd.Flags().Changed("step")
 			setRunFlags.TTY = cmd.Flags().Changed("tty")
+			setRunFlags.PTY = cmd.Flags().Changed("pty")
+			setRunFlags.Stdin = cmd.Flags().Changed("stdin")
 			setRunFlags.Tags = cmd.Flags().Changed("tags")
 			setRunFlags.Verbose = cmd.Flags().Changed("verbose")
 			setRunFlags.MaxFailPercentage = cmd.Flags().Changed("max-fail-percentage")
@@ -176,6 +182,7 @@ func runCmd(config *dao.Config, configErr *error) *cobra.Command {
 	cmd.Flags().BoolVarP(&runFlags.All, "all", "a", false, "target all hosts")
 	cmd.Flags().BoolVarP(&runFlags.Invert, "invert", "v", false, "invert matching on hosts")
 	cmd.Flags().StringVarP(&runFlags.Regex, "regex", "r", "", "target hosts on host regex")
+	cmd.Flags().StringArrayVar(&runFlags.Where, "where", []string{}, "target hosts by field or env, for instance user=deploy, port!=22, env.REGION=eu-* or bastion~=jump1")
 
 	cmd.Flags().StringSliceVarP(&runFlags.Servers, "servers", "s", []string{}, "target servers by names")
 	err = cmd.RegisterFlagCompletionFunc("servers", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -187,7 +194,7 @@ func runCmd(config *dao.Config, configErr *error) *cobra.Command {
 	})
 	core.CheckIfError(err)
 
-	cmd.Flags().StringSliceVarP(&runFlags.Tags, "tags", "t", []string{}, "target hosts by tags")
+	cmd.Flags().StringSliceVarP(&runFlags.Tags, "tags", "t", []string{}, "target hosts by tags or tag expressions")
 	err = cmd.RegisterFlagCompletionFunc("tags", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if *configErr != nil {
 			return []string{}, cobra.ShellCompDirectiveDefault
@@ -197,7 +204,7 @@ func runCmd(config *dao.Config, configErr *error) *cobra.Command {
 	})
 	core.CheckIfError(err)
 
-	cmd.Flags().StringVarP(&runFlags.Target, "target", "T", "", "target hosts by target name")
+	cmd.Flags().StringSliceVarP(&runFlags.Target, "target", "T", []string{}, "target hosts by target names, multiple targets are combined")
 	err = cmd.RegisterFlagCompletionFunc("target", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if *configErr != nil {
 			return []string{}, cobra.ShellCompDirectiveDefault
@@ -272,9 +279,13 @@ func runCmd(config *dao.Config, configErr *error) *cobra.Command {
 	core.CheckIfError(err)
 
 	cmd.Flags().BoolVar(&runFlags.TTY, "tty", false, "replace the current process")
+	cmd.Flags().BoolVar(&runFlags.PTY, "pty", false, "allocate a pseudo-terminal on the remote host")
 	cmd.Flags().BoolVar(&runFlags.Attach, "attach", false, "ssh to server after command")
 	cmd.Flags().BoolVar(&runFlags.Local, "local", false, "run task on localhost")
+	cmd.Flags().StringVar(&runFlags.Stdin, "stdin", "", "send file content to stdin of each command, use - to read from stdin")
 	cmd.MarkFlagsMutuallyExclusive("tty", "attach", "local")
+	cmd.MarkFlagsMutuallyExclusive("tty", "pty")
+	cmd.MarkFlagsMutuallyExclusive("stdin", "tty", "pty")
 	cmd.Flags().BoolVarP(&runFlags.Edit, "edit", "e", false, "edit task")
 
 	cmd.Flags().StringSliceVarP(&runFlags.Report, "report", "R", []string{"recap"}, "reports to show")
@@ -325,17 +336,23 @@ func runTask(
 			task, err := config.GetTask(taskID)
 			core.CheckIfError(err)
 
-			err = config.ParseInventory(userArgs)
+			// Resolve params before any inventory command runs or connection is made
+			taskArgs, err := run.ResolveTaskParams(*config, *task, userArgs, taskIDs)
+			core.CheckIfError(err)
+
+			// Each task parses the inventory with its own arguments
+			taskConfig := *config
+			err = taskConfig.ParseInventory(taskArgs)
 			core.CheckIfError(err)
 
-			servers, err := config.GetTaskServers(task, runFlags, setRunFlags)
+			servers, err := taskConfig.GetTaskServers(task, runFlags, setRunFlags)
 			core.CheckIfError(err)
 
 			if len(servers) == 0 {
 				fmt.Println("No targets")
 			} else {
-				target := run.Run{Servers: servers, Task: task, Config: *config}
-				err := target.RunTask(userArgs, runFlags, setRunFlags)
+				target := run.Run{Servers: servers, Task: task, Config: taskConfig}
+				err := target.RunTask(taskArgs, runFlags, setRunFlags)
 				core.CheckIfError(err)
 			}
 		}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alajmo/sake/core"
+	"github.com/alajmo/sake/core/dao"
+)
+
+func vaultCmd() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "vault",
+		Short: "Manage encrypted vault files",
+		Long: `Manage encrypted vault files.
+
+A vault file holds a YAML dictionary of secrets, which are referenced via secret://<provider>/<key>
+once the vault is added as a secret provider. The passphrase is read from SAKE_VAULT_PASSWORD or prompted for.`,
+		Example: `  # Encrypt a file in place
+  sake vault encrypt secrets.vault
+
+  # Decrypt a file in place
+  sake vault decrypt secrets.vault
+
+  # Print the decrypted content
+  sake vault view secrets.vault`,
+		DisableAutoGenTag: true,
+	}
+
+	cmd.AddCommand(
+		vaultEncryptCmd(),
+		vaultDecryptCmd(),
+		vaultViewCmd(),
+	)
+
+	return &cmd
+}
+
+func vaultEncryptCmd() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "encrypt <file>",
+		Short: "Encrypt a file in place",
+		Long:  "Encrypt a file in place.",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			data, err := os.ReadFile(args[0])
+			core.CheckIfError(err)
+
+			if core.IsVault(data) {
+				core.Exit(fmt.Errorf("`%s` is already encrypted", args[0]))
+			}
+
+			passphrase, err := newVaultPassphrase(args[0])
+			core.CheckIfError(err)
+
+			vault, err := core.EncryptVault(data, passphrase)
+			core.CheckIfError(err)
+
+			err = os.WriteFile(args[0], vault, 0600)
+			core.CheckIfError(err)
+		},
+		DisableAutoGenTag: true,
+	}
+
+	return &cmd
+}
+
+func vaultDecryptCmd() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "decrypt <file>",
+		Short: "Decrypt a file in place",
+		Long:  "Decrypt a file in place.",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			data := decryptVaultFile(args[0])
+			err := os.WriteFile(args[0], data, 0600)
+			core.CheckIfError(err)
+		},
+		DisableAutoGenTag: true,
+	}
+
+	return &cmd
+}
+
+func vaultViewCmd() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "view <file>",
+		Short: "Print the decrypted content of a file",
+		Long:  "Print the decrypted content of a file.",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Print(string(decryptVaultFile(args[0])))
+		},
+		DisableAutoGenTag: true,
+	}
+
+	return &cmd
+}
+
+func decryptVaultFile(path string) []byte {
+	vault, err := os.ReadFile(path)
+	core.CheckIfError(err)
+
+	passphrase, err := vaultPassphrase(path)
+	core.CheckIfError(err)
+
+	data, err := core.DecryptVault(vault, passphrase)
+	core.CheckIfError(err)
+
+	return data
+}
+
+func vaultPassphrase(path string) (string, error) {
+	if passphrase, ok := os.LookupEnv("SAKE_VAULT_PASSWORD"); ok {
+		return passphrase, nil
+	}
+
+	return dao.PromptVaultPassphrase(path)
+}
+
+// newVaultPassphrase prompts twice for the passphrase, unless it's set via SAKE_VAULT_PASSWORD.
+func newVaultPassphrase(path string) (string, error) {
+	if passphrase, ok := os.LookupEnv("SAKE_VAULT_PASSWORD"); ok {
+		return passphrase, nil
+	}
+
+	passphrase, err := dao.PromptVaultPassphrase(path)
+	if err != nil {
+		return "", err
+	}
+
+	confirm, err := dao.PromptVaultPassphrase(path)
+	if err != nil {
+		return "", err
+	}
+
+	if passphrase != confirm {
+		return "", errors.New("passphrases do not match")
+	}
+
+	if passphrase == "" {
+		return "", errors.New("passphrase cannot be empty")
+	}
+
+	return passphrase, nil
+}
@@ -60,19 +60,51 @@ func FormatErrors(re Resource, errs []error) error {
 
 // ENV
 
-func ParseNodeEnv(node yaml.Node) []string {
+// Long form of an env variable, used to mark its value as secret:
+//
+//	env:
+//	  DB_PASSWORD:
+//	    value: $(pass show db)
+//	    secret: true
+type EnvYAML struct {
+	Value  string `yaml:"value"`
+	Secret bool   `yaml:"secret"`
+}
+
+// ParseNodeEnv parses an env mapping and returns the env variables and the names of the
+// variables marked as secret via the long form `{ value: <value>, secret: true }`.
+func ParseNodeEnv(node yaml.Node) ([]string, []string, error) {
 	var envs []string
+	var secrets []string
 	count := len(node.Content)
 
 	for i := 0; i < count; i += 2 {
-		env := fmt.Sprintf("%v=%v", node.Content[i].Value, node.Content[i+1].Value)
-		envs = append(envs, env)
+		name := node.Content[i].Value
+		value := node.Content[i+1].Value
+
+		if node.Content[i+1].Kind == yaml.MappingNode {
+			envYAML := EnvYAML{}
+			if err := node.Content[i+1].Decode(&envYAML); err != nil {
+				return envs, secrets, fmt.Errorf("env `%s`: %w", name, err)
+			}
+			value = envYAML.Value
+			if envYAML.Secret {
+				secrets = append(secrets, name)
+			}
+		}
+
+		envs = append(envs, fmt.Sprintf("%v=%v", name, value))
 	}
 
-	return envs
+	return envs, secrets, nil
 }
 
+// EvaluateEnv evaluates command substitutions `$(...)` and resolves secret references `secret://...`.
 func EvaluateEnv(envList []string) ([]string, error) {
+	return evaluateEnv(envList, true)
+}
+
+func evaluateEnv(envList []string, resolveSecrets bool) ([]string, error) {
 	var envs []string
 
 	for _, arg := range envList {
@@ -90,6 +122,13 @@ func EvaluateEnv(envList []string) ([]string, error) {
 			}
 
 			envs = append(envs, fmt.Sprintf("%v=%v", kv[0], string(out)))
+		} else if resolveSecrets && IsSecretRef(kv[1]) {
+			value, err := ResolveSecret(kv[1])
+			if err != nil {
+				return envs, &core.ConfigEnvFailed{Name: kv[0], Err: err.Error()}
+			}
+
+			envs = append(envs, fmt.Sprintf("%v=%v", kv[0], value))
 		} else {
 			envs = append(envs, fmt.Sprintf("%v=%v", kv[0], kv[1]))
 		}
@@ -125,6 +164,31 @@ func MergeEnvs(envs ...[]string) []string {
 	return mergedEnvs
 }
 
+// VARS
+
+// ParseNodeVars decodes a vars mapping, values keep their YAML type (lists, maps, numbers, ...).
+func ParseNodeVars(node yaml.Node) (map[string]any, error) {
+	vars := make(map[string]any)
+	if err := node.Decode(&vars); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+// MergeVars merges vars, top-level keys are not merged recursively.
+// Priority is from highest to lowest (1st vars takes precedence over the last entry).
+func MergeVars(vars ...map[string]any) map[string]any {
+	merged := make(map[string]any)
+	for i := len(vars) - 1; i >= 0; i-- {
+		for k, v := range vars[i] {
+			merged[k] = v
+		}
+	}
+
+	return merged
+}
+
 // SelectFirstNonEmpty selects first non-empty string.
 func SelectFirstNonEmpty(values ...string) string {
 	for _, w := range values {
@@ -165,6 +229,10 @@ func CheckIsScalarNode(node yaml.Node) error {
 }
 
 func EvaluatePassword(password string) (string, error) {
+	if IsSecretRef(password) {
+		return ResolveSecret(password)
+	}
+
 	if strings.HasPrefix(password, "$(") && strings.HasSuffix(password, ")") {
 		password = strings.TrimPrefix(password, "$(")
 		password = strings.TrimSuffix(password, ")")
@@ -20,7 +20,10 @@ env:
 		t.Fatalf("%q", err)
 	}
 
-	envs := ParseNodeEnv(configYAML.Env)
+	envs, _, err := ParseNodeEnv(configYAML.Env)
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
 
 	wanted := []string{
 		"foo=bar",
@@ -72,12 +72,19 @@ type Config struct {
 	KnownHostsFile    string
 	Shell             string
 	Envs              []string
+	SecretEnvs        []string // names of envs marked as secret
+	Vars              map[string]any
 	Themes            []Theme
 	Specs             []Spec
 	Targets           []Target
 	Servers           []Server
+	ServerGroups      []ServerGroup
 	Tasks             []Task
+	Secrets           []Secret
 	Path              string
+
+	// Run inventory commands instead of reading their output from the cache
+	RefreshInventory bool
 }
 
 type ConfigYAML struct {
@@ -93,11 +100,14 @@ type ConfigYAML struct {
 	Shell             string    `yaml:"shell"`
 	Import            yaml.Node `yaml:"import"`
 	Env               yaml.Node `yaml:"env"`
+	Vars              yaml.Node `yaml:"vars"`
 	Themes            yaml.Node `yaml:"themes"`
 	Specs             yaml.Node `yaml:"specs"`
 	Targets           yaml.Node `yaml:"targets"`
 	Servers           yaml.Node `yaml:"servers"`
+	ServerGroups      yaml.Node `yaml:"server_groups"`
 	Tasks             yaml.Node `yaml:"tasks"`
+	Secrets           yaml.Node `yaml:"secrets"`
 
 	contextLine int `yaml:"-"`
 }
@@ -573,8 +583,19 @@ func (c *Config) ParseInventory(userArgs []string) error {
 	shell = core.FormatShell(shell)
 
 	for _, s := range c.Servers {
-		if s.Inventory != "" {
-			hosts, err := core.EvaluateInventory(shell, s.context, s.Inventory, s.Envs, userArgs)
+		var hosts []InventoryHost
+		switch {
+		case s.Inventory != "":
+			out, err := runInventory(shell, s, userArgs, c.RefreshInventory)
+			if err != nil {
+				return err
+			}
+
+			if s.InventoryFormat == InventoryFormatAnsible {
+				hosts, err = ParseAnsibleInventory(s.Name, out)
+			} else {
+				hosts, err = ParseInventoryOutput(s.Name, out)
+			}
 			if err != nil {
 				return err
 			}
@@ -582,19 +603,34 @@ func (c *Config) ParseInventory(userArgs []string) error {
 			if len(hosts) == 0 {
 				return fmt.Errorf("inventory %s returned 0 hosts", s.Name)
 			}
+		case s.FromSSHConfig != "":
+			var err error
+			hosts, err = ParseSSHConfigInventory(c.SSHConfigFile, s)
+			if err != nil {
+				return err
+			}
+		default:
+			servers = append(servers, s)
+			continue
+		}
 
-			for i, host := range hosts {
-				server, err := CreateInventoryServers(host, i, s, userArgs)
-				if err != nil {
-					return err
-				}
-
-				servers = append(servers, server)
+		for i, host := range hosts {
+			server, err := CreateInventoryServer(host, i, s, userArgs)
+			if err != nil {
+				return err
 			}
 
-		} else {
-			servers = append(servers, s)
+			servers = append(servers, server)
+		}
+	}
+
+	// Inventory hosts may be named, so check that names are still unique
+	names := make(map[string]string)
+	for _, s := range servers {
+		if group, found := names[s.Name]; found {
+			return &core.InventoryDuplicateServer{Name: s.Name, Groups: []string{group, s.Group}}
 		}
+		names[s.Name] = s.Group
 	}
 
 	c.Servers = servers
@@ -0,0 +1,312 @@
+package dao
+
+import (
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/sake/core"
+)
+
+// Keys that are never inherited from a parent resource.
+var extendsSkipKeys = []string{"_", "name", "extends"}
+
+// Keys of a task that define what it runs, only one of them is allowed per task, so a child
+// task that defines one of them does not inherit any of them.
+var extendsTaskCmdKeys = []string{"cmd", "task", "tasks"}
+
+// resolveExtends resolves `extends` for specs, targets, themes and tasks. The definition of each
+// resource is merged with the definitions of its parents, child keys taking precedence, and then
+// decoded again.
+func (cr *ConfigResources) resolveExtends() {
+	for i := range cr.Specs {
+		if cr.Specs[i].Extends == "" {
+			continue
+		}
+
+		spec := cr.Specs[i]
+		node, err := resolveExtendsNode("spec", spec.Name, cr.lookupSpec, mergeNodes)
+		if err != nil {
+			cr.setSpecErrors(spec, []error{err})
+			continue
+		}
+
+		c := ConfigYAML{Path: spec.context}
+		decoded, errs := c.DecodeSpec(spec.Name, *node)
+		decoded.contextLine = spec.contextLine
+		decoded.node = spec.node
+		decoded.namespace = spec.namespace
+		cr.Specs[i] = *decoded
+		cr.setSpecErrors(spec, errs)
+	}
+
+	for i := range cr.Targets {
+		if cr.Targets[i].Extends == "" {
+			continue
+		}
+
+		target := cr.Targets[i]
+		node, err := resolveExtendsNode("target", target.Name, cr.lookupTarget, mergeNodes)
+		if err != nil {
+			cr.setTargetErrors(target, []error{err})
+			continue
+		}
+
+		c := ConfigYAML{Path: target.context}
+		decoded, errs := c.DecodeTarget(target.Name, *node)
+		decoded.Name = target.Name
+		decoded.contextLine = target.contextLine
+		decoded.node = target.node
+		decoded.namespace = target.namespace
+		cr.Targets[i] = *decoded
+		cr.setTargetErrors(target, errs)
+	}
+
+	for i := range cr.Themes {
+		if cr.Themes[i].Extends == "" {
+			continue
+		}
+
+		theme := cr.Themes[i]
+		node, err := resolveExtendsNode("theme", theme.Name, cr.lookupTheme, mergeNodes)
+		if err != nil {
+			cr.setThemeErrors(theme, []error{err})
+			continue
+		}
+
+		c := ConfigYAML{Path: theme.context}
+		decoded, errs := c.DecodeTheme(theme.Name, node)
+		decoded.Name = theme.Name
+		decoded.contextLine = theme.contextLine
+		decoded.node = theme.node
+		decoded.namespace = theme.namespace
+		cr.Themes[i] = *decoded
+		cr.setThemeErrors(theme, errs)
+	}
+
+	for i := range cr.Tasks {
+		if cr.Tasks[i].Extends == "" {
+			continue
+		}
+
+		task := cr.Tasks[i]
+		node, err := resolveExtendsNode("task", task.ID, cr.lookupTask, mergeTaskNodes)
+		if err != nil {
+			cr.setTaskErrors(task, []error{err})
+			continue
+		}
+
+		c := ConfigYAML{Path: task.context}
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: task.ID, Line: task.contextLine}
+		decoded, errs, ok := c.DecodeTask(key, node)
+		if ok {
+			decoded.node = task.node
+			decoded.namespace = task.namespace
+			cr.Tasks[i] = *decoded
+		}
+		cr.setTaskErrors(task, errs)
+	}
+}
+
+// resolveExtendsNode returns the definition of a resource merged with the definitions of its
+// parents. lookup returns the definition and parent of a resource.
+func resolveExtendsNode(
+	kind string,
+	name string,
+	lookup func(name string) (*yaml.Node, string, bool),
+	merge func(parent *yaml.Node, child *yaml.Node) *yaml.Node,
+) (*yaml.Node, error) {
+	node, parent, _ := lookup(name)
+	chain := []*yaml.Node{node}
+	visited := []string{name}
+
+	for parent != "" {
+		if slices.Contains(visited, parent) {
+			return nil, &core.ExtendsCycle{Kind: kind, Names: append(visited, parent)}
+		}
+
+		n, p, found := lookup(parent)
+		if !found {
+			return nil, &core.ExtendsNotFound{Kind: kind, Name: visited[len(visited)-1], Parent: parent}
+		}
+
+		visited = append(visited, parent)
+		chain = append(chain, n)
+		parent = p
+	}
+
+	merged := chain[len(chain)-1]
+	for i := len(chain) - 2; i >= 0; i-- {
+		merged = merge(merged, chain[i])
+	}
+
+	return merged, nil
+}
+
+// mergeNodes merges the parent and child mappings, child values take precedence.
+func mergeNodes(parent *yaml.Node, child *yaml.Node) *yaml.Node {
+	return mergeMappings(parent, child, extendsSkipKeys)
+}
+
+// mergeTaskNodes merges tasks like mergeNodes, except that cmd, task and tasks are not
+// inherited if the child defines any of them.
+func mergeTaskNodes(parent *yaml.Node, child *yaml.Node) *yaml.Node {
+	// Shorthand definition, ping: echo 123
+	if parent.Kind == yaml.ScalarNode {
+		parent = &yaml.Node{
+			Kind: yaml.MappingNode,
+			Tag:  "!!map",
+			Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Tag: "!!str", Value: "cmd"},
+				parent,
+			},
+		}
+	}
+
+	skip := extendsSkipKeys
+	for _, key := range extendsTaskCmdKeys {
+		if mappingValue(child, key) != nil {
+			skip = append(slices.Clone(extendsSkipKeys), extendsTaskCmdKeys...)
+			break
+		}
+	}
+
+	return mergeMappings(parent, child, skip)
+}
+
+// mergeMappings merges nested mappings recursively, all other values in child replace the ones
+// in parent. Keys in skip are not inherited from parent.
+func mergeMappings(parent *yaml.Node, child *yaml.Node, skip []string) *yaml.Node {
+	if parent == nil || parent.Kind != yaml.MappingNode || child.Kind != yaml.MappingNode {
+		return child
+	}
+
+	merged := *child
+	merged.Content = []*yaml.Node{}
+
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		key := parent.Content[i]
+		if slices.Contains(skip, key.Value) {
+			continue
+		}
+
+		value := parent.Content[i+1]
+		if childValue := mappingValue(child, key.Value); childValue != nil {
+			value = mergeMappings(value, childValue, nil)
+		}
+		merged.Content = append(merged.Content, key, value)
+	}
+
+	for i := 0; i+1 < len(child.Content); i += 2 {
+		if mappingValue(parent, child.Content[i].Value) == nil || slices.Contains(skip, child.Content[i].Value) {
+			merged.Content = append(merged.Content, child.Content[i], child.Content[i+1])
+		}
+	}
+
+	return &merged
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// encodeNode returns the definition of a resource that was not read from a config,
+// such as the default spec, target and theme.
+func encodeNode(v any) *yaml.Node {
+	node := &yaml.Node{}
+	if err := node.Encode(v); err != nil {
+		return &yaml.Node{Kind: yaml.MappingNode}
+	}
+	return node
+}
+
+func (cr *ConfigResources) lookupSpec(name string) (*yaml.Node, string, bool) {
+	for _, spec := range cr.Specs {
+		if spec.Name == name {
+			if spec.node == nil {
+				return encodeNode(spec), spec.Extends, true
+			}
+			return spec.node, spec.Extends, true
+		}
+	}
+	return nil, "", false
+}
+
+func (cr *ConfigResources) lookupTarget(name string) (*yaml.Node, string, bool) {
+	for _, target := range cr.Targets {
+		if target.Name == name {
+			if target.node == nil {
+				return encodeNode(target), target.Extends, true
+			}
+			return target.node, target.Extends, true
+		}
+	}
+	return nil, "", false
+}
+
+func (cr *ConfigResources) lookupTheme(name string) (*yaml.Node, string, bool) {
+	for _, theme := range cr.Themes {
+		if theme.Name == name {
+			if theme.node == nil {
+				return encodeNode(theme), theme.Extends, true
+			}
+			return theme.node, theme.Extends, true
+		}
+	}
+	return nil, "", false
+}
+
+func (cr *ConfigResources) lookupTask(id string) (*yaml.Node, string, bool) {
+	for _, task := range cr.Tasks {
+		if task.ID == id {
+			return task.node, task.Extends, true
+		}
+	}
+	return nil, "", false
+}
+
+func (cr *ConfigResources) setSpecErrors(spec Spec, errs []error) {
+	for i := range cr.SpecErrors {
+		if cr.SpecErrors[i].Resource.Name == spec.Name && cr.SpecErrors[i].Resource.context == spec.context {
+			cr.SpecErrors[i].Errors = errs
+			return
+		}
+	}
+}
+
+func (cr *ConfigResources) setTargetErrors(target Target, errs []error) {
+	for i := range cr.TargetErrors {
+		if cr.TargetErrors[i].Resource.Name == target.Name && cr.TargetErrors[i].Resource.context == target.context {
+			cr.TargetErrors[i].Errors = errs
+			return
+		}
+	}
+}
+
+func (cr *ConfigResources) setThemeErrors(theme Theme, errs []error) {
+	for i := range cr.ThemeErrors {
+		if cr.ThemeErrors[i].Resource.Name == theme.Name && cr.ThemeErrors[i].Resource.context == theme.context {
+			cr.ThemeErrors[i].Errors = errs
+			return
+		}
+	}
+}
+
+func (cr *ConfigResources) setTaskErrors(task Task, errs []error) {
+	for i := range cr.TaskErrors {
+		if cr.TaskErrors[i].Resource.ID == task.ID && cr.TaskErrors[i].Resource.context == task.context {
+			cr.TaskErrors[i].Errors = errs
+			return
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package dao
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestResolveExtendsNode(t *testing.T) {
+	var data = `
+base:
+  cmd: echo base
+  desc: base task
+  env:
+    A: a
+    B: b
+child:
+  extends: base
+  env:
+    B: bb
+grandchild:
+  extends: child
+  tasks:
+    - cmd: echo grandchild
+loop-a:
+  extends: loop-b
+loop-b:
+  extends: loop-a
+missing:
+  extends: nope
+`
+	configYAML := ConfigYAML{}
+	if err := yaml.Unmarshal([]byte(data), &configYAML.Tasks); err != nil {
+		t.Fatalf("%q", err)
+	}
+	configYAML.Tasks = *configYAML.Tasks.Content[0]
+
+	cr := ConfigResources{}
+	cr.Tasks, _ = configYAML.ParseTasksYAML()
+	cr.resolveExtends()
+
+	child, err := cr.GetTask("child")
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	if child.Name != "child" || child.Desc != "base task" || child.Cmd != "echo base" {
+		t.Fatalf("Wanted: inherited desc and cmd, Found: %+v", child)
+	}
+	wanted := []string{"A=a", "B=bb"}
+	if len(child.Envs) != len(wanted) || child.Envs[0] != wanted[0] || child.Envs[1] != wanted[1] {
+		t.Fatalf("Wanted: %q, Found: %q", wanted, child.Envs)
+	}
+
+	grandchild, _ := cr.GetTask("grandchild")
+	if grandchild.Cmd != "" || len(grandchild.TaskRefs) != 1 {
+		t.Fatalf("Wanted: tasks to replace inherited cmd, Found: %+v", grandchild)
+	}
+
+	for _, id := range []string{"loop-a", "missing"} {
+		if _, err := resolveExtendsNode("task", id, cr.lookupTask, mergeTaskNodes); err == nil {
+			t.Fatalf("Wanted: error for %q", id)
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 type Import struct {
 	Path string
+	As   string
 
 	context     string
 	contextLine int
@@ -38,22 +39,40 @@ type ConfigResources struct {
 	Targets           []Target
 	Tasks             []Task
 	Servers           []Server
+	ServerGroups      []ServerGroup
+	Secrets           []Secret
 	Envs              []string
+	SecretEnvs        []string
+	Vars              map[string]any
+	LockPath          string
+	Lock              *ImportLock
+
+	// Servers are parsed once all server groups are loaded
+	serverConfigs []*ConfigYAML
+
+	ConfigErrors      []ResourceErrors[ConfigYAML]
+	ImportErrors      []ResourceErrors[Import]
+	ThemeErrors       []ResourceErrors[Theme]
+	SpecErrors        []ResourceErrors[Spec]
+	TargetErrors      []ResourceErrors[Target]
+	TaskErrors        []ResourceErrors[Task]
+	ServerErrors      []ResourceErrors[Server]
+	ServerGroupErrors []ResourceErrors[ServerGroup]
+	SecretErrors      []ResourceErrors[Secret]
+}
 
-	ConfigErrors []ResourceErrors[ConfigYAML]
-	ImportErrors []ResourceErrors[Import]
-	ThemeErrors  []ResourceErrors[Theme]
-	SpecErrors   []ResourceErrors[Spec]
-	TargetErrors []ResourceErrors[Target]
-	TaskErrors   []ResourceErrors[Task]
-	ServerErrors []ResourceErrors[Server]
+// Unmarshaled from YAML
+type ImportYAML struct {
+	Path string `yaml:"path"`
+	As   string `yaml:"as"`
 }
 
 type Node struct {
-	Path     string
-	Imports  []Import
-	Visiting bool
-	Visited  bool
+	Path      string
+	Namespace string
+	Imports   []Import
+	Visiting  bool
+	Visited   bool
 }
 
 type NodeLink struct {
@@ -88,6 +107,7 @@ func (c *FoundCyclicDependency) Error() string {
 //     3.1. Create default Theme collection
 //     3.2. Create default Spec collection
 //     3.3. Create default Target collection
+//     3.4. Merge specs, targets, themes and tasks with the resources they extend
 //     4. Perform a depth-first search for task references and save them as T
 //     5. We check duplicate server hosts in the config collection
 //
@@ -98,7 +118,7 @@ func (c *FoundCyclicDependency) Error() string {
 // with an error containing the cyclic dependency found.
 func (c *ConfigYAML) parseConfig() (Config, error) {
 	// Main config
-	cr := ConfigResources{}
+	cr := ConfigResources{LockPath: filepath.Join(c.Dir, IMPORT_LOCK_FILE)}
 
 	cr.Envs = []string{
 		fmt.Sprintf("SAKE_DIR=%s", c.Dir),
@@ -140,6 +160,23 @@ func (c *ConfigYAML) parseConfig() (Config, error) {
 	importCycles := []NodeLink{}
 	dfsImport(&n, m, &importCycles, &cr)
 
+	// Pin new git imports and drop unused ones
+	if cr.Lock != nil {
+		if err := cr.Lock.Save(); err != nil {
+			configError := ResourceErrors[ConfigYAML]{Resource: c, Errors: []error{err}}
+			cr.ConfigErrors = append(cr.ConfigErrors, configError)
+		}
+	}
+
+	// Servers may extend server groups from any config
+	cr.resolveServerGroups()
+	for _, cfg := range cr.serverConfigs {
+		servers, serverErrors := cfg.ParseServersYAML(&cr)
+		cr.Servers = append(cr.Servers, servers...)
+		cr.ServerErrors = append(cr.ServerErrors, serverErrors...)
+	}
+	cr.setServerGroupServers()
+
 	// Create default config if not exists
 	_, err := cr.GetTheme(DEFAULT_THEME.Name)
 	if err != nil {
@@ -159,6 +196,23 @@ func (c *ConfigYAML) parseConfig() (Config, error) {
 		cr.Targets = append(cr.Targets, DEFAULT_TARGET)
 	}
 
+	// Merge specs, targets, themes and tasks with the resources they extend, references are
+	// resolved again since extended resources are decoded from their definitions
+	cr.resolveNamespaces()
+	cr.resolveExtends()
+	cr.resolveNamespaces()
+
+	// Check targets referenced by targets
+	for i := range cr.Targets {
+		if err := cr.checkTargetRefs(cr.Targets[i], []string{cr.Targets[i].Name}); err != nil {
+			for j := range cr.TargetErrors {
+				if cr.TargetErrors[j].Resource.Name == cr.Targets[i].Name && cr.TargetErrors[j].Resource.context == cr.Targets[i].context {
+					cr.TargetErrors[j].Errors = append(cr.TargetErrors[j].Errors, err)
+				}
+			}
+		}
+	}
+
 	// Process tasks:
 	//  - Expand references (targets, specs, themes, tasks)
 	//  - Check for cyclic dependencies for tasks
@@ -189,20 +243,26 @@ func (c *ConfigYAML) parseConfig() (Config, error) {
 			} else {
 				cr.Tasks[i].Target = *target
 			}
+		} else if err := cr.checkTargetRefs(cr.Tasks[i].Target, []string{}); err != nil {
+			cr.TaskErrors[i].Errors = append(cr.TaskErrors[i].Errors, err)
 		}
 
 		if cr.Tasks[i].Cmd != "" {
 			taskCmd := TaskCmd{
-				ID:      cr.Tasks[i].ID,
-				Name:    cr.Tasks[i].Name,
-				Desc:    cr.Tasks[i].Desc,
-				RootDir: filepath.Dir(cr.Tasks[i].context),
-				WorkDir: cr.Tasks[i].WorkDir,
-				Cmd:     cr.Tasks[i].Cmd,
-				Local:   cr.Tasks[i].Local,
-				Shell:   cr.Tasks[i].Shell,
-				TTY:     cr.Tasks[i].TTY,
-				Envs:    cr.Tasks[i].Envs,
+				ID:            cr.Tasks[i].ID,
+				Name:          cr.Tasks[i].Name,
+				Desc:          cr.Tasks[i].Desc,
+				RootDir:       filepath.Dir(cr.Tasks[i].context),
+				WorkDir:       cr.Tasks[i].WorkDir,
+				Cmd:           cr.Tasks[i].Cmd,
+				CmdTemplate:   cr.Tasks[i].CmdTemplate,
+				Local:         cr.Tasks[i].Local,
+				Shell:         cr.Tasks[i].Shell,
+				TTY:           cr.Tasks[i].TTY,
+				PTY:           cr.Tasks[i].PTY,
+				LocalEnvClear: cr.Tasks[i].LocalEnvClear,
+				Envs:          cr.Tasks[i].Envs,
+				Vars:          cr.Tasks[i].Vars,
 			}
 			cr.Tasks[i].Tasks = append(cr.Tasks[i].Tasks, taskCmd)
 		} else {
@@ -219,15 +279,21 @@ func (c *ConfigYAML) parseConfig() (Config, error) {
 
 	// Create config
 	var config = Config{
-		Tasks:   cr.Tasks,
-		Servers: cr.Servers,
-		Themes:  cr.Themes,
-		Specs:   cr.Specs,
-		Targets: cr.Targets,
-		Envs:    cr.Envs,
-		Path:    c.Path,
+		Tasks:        cr.Tasks,
+		Servers:      cr.Servers,
+		ServerGroups: cr.ServerGroups,
+		Themes:       cr.Themes,
+		Specs:        cr.Specs,
+		Targets:      cr.Targets,
+		Secrets:      cr.Secrets,
+		Envs:         cr.Envs,
+		SecretEnvs:   cr.SecretEnvs,
+		Vars:         cr.Vars,
+		Path:         c.Path,
 	}
 
+	SetSecrets(config.Secrets)
+
 	if cr.DisableVerifyHost == nil {
 		config.DisableVerifyHost = false
 	} else {
@@ -297,12 +363,24 @@ func concatErrors(importErr string, duplicateObjects string, cr ConfigResources,
 		}
 	}
 
+	for _, group := range cr.ServerGroupErrors {
+		if len(group.Errors) > 0 {
+			errString = fmt.Sprintf("%s%s", errString, FormatErrors(group.Resource, group.Errors))
+		}
+	}
+
 	for _, server := range cr.ServerErrors {
 		if len(server.Errors) > 0 {
 			errString = fmt.Sprintf("%s%s", errString, FormatErrors(server.Resource, server.Errors))
 		}
 	}
 
+	for _, secret := range cr.SecretErrors {
+		if len(secret.Errors) > 0 {
+			errString = fmt.Sprintf("%s%s", errString, FormatErrors(secret.Resource, secret.Errors))
+		}
+	}
+
 	for _, theme := range cr.ThemeErrors {
 		if len(theme.Errors) > 0 {
 			errString = fmt.Sprintf("%s%s", errString, FormatErrors(theme.Resource, theme.Errors))
@@ -427,9 +505,25 @@ func (c *ConfigYAML) loadResources(cr *ConfigResources) {
 			}
 			cr.ConfigErrors = append(cr.ConfigErrors, configError)
 		} else {
-			servers, serverErrors := c.ParseServersYAML()
-			cr.Servers = append(cr.Servers, servers...)
-			cr.ServerErrors = append(cr.ServerErrors, serverErrors...)
+			cr.serverConfigs = append(cr.serverConfigs, c)
+		}
+	}
+
+	// Server groups
+	if !IsNullNode(c.ServerGroups) {
+		err := CheckIsMappingNode(c.ServerGroups)
+		if err != nil {
+			cfg := *c
+			cfg.contextLine = c.ServerGroups.Line
+			configError := ResourceErrors[ConfigYAML]{
+				Resource: &cfg,
+				Errors:   []error{err},
+			}
+			cr.ConfigErrors = append(cr.ConfigErrors, configError)
+		} else {
+			groups, groupErrors := c.ParseServerGroupsYAML()
+			cr.ServerGroups = append(cr.ServerGroups, groups...)
+			cr.ServerGroupErrors = append(cr.ServerGroupErrors, groupErrors...)
 		}
 	}
 
@@ -487,6 +581,24 @@ func (c *ConfigYAML) loadResources(cr *ConfigResources) {
 		}
 	}
 
+	// Secrets
+	if !IsNullNode(c.Secrets) {
+		err := CheckIsMappingNode(c.Secrets)
+		if err != nil {
+			cfg := *c
+			cfg.contextLine = c.Secrets.Line
+			configError := ResourceErrors[ConfigYAML]{
+				Resource: &cfg,
+				Errors:   []error{err},
+			}
+			cr.ConfigErrors = append(cr.ConfigErrors, configError)
+		} else {
+			secrets, secretErrors := c.ParseSecretsYAML()
+			cr.Secrets = append(cr.Secrets, secrets...)
+			cr.SecretErrors = append(cr.SecretErrors, secretErrors...)
+		}
+	}
+
 	// Envs
 	if !IsNullNode(c.Env) {
 		err := CheckIsMappingNode(c.Env)
@@ -499,8 +611,39 @@ func (c *ConfigYAML) loadResources(cr *ConfigResources) {
 			}
 			cr.ConfigErrors = append(cr.ConfigErrors, configError)
 		} else {
-			envs := ParseNodeEnv(c.Env)
+			envs, secrets, err := ParseNodeEnv(c.Env)
+			if err != nil {
+				cfg := *c
+				cfg.contextLine = c.Env.Line
+				configError := ResourceErrors[ConfigYAML]{
+					Resource: &cfg,
+					Errors:   []error{err},
+				}
+				cr.ConfigErrors = append(cr.ConfigErrors, configError)
+			}
 			cr.Envs = append(cr.Envs, envs...)
+			cr.SecretEnvs = append(cr.SecretEnvs, secrets...)
+		}
+	}
+
+	// Vars
+	if !IsNullNode(c.Vars) {
+		var vars map[string]any
+		err := CheckIsMappingNode(c.Vars)
+		if err == nil {
+			vars, err = ParseNodeVars(c.Vars)
+		}
+		if err != nil {
+			cfg := *c
+			cfg.contextLine = c.Vars.Line
+			configError := ResourceErrors[ConfigYAML]{
+				Resource: &cfg,
+				Errors:   []error{err},
+			}
+			cr.ConfigErrors = append(cr.ConfigErrors, configError)
+		} else {
+			// Vars from the main config take precedence over imported vars
+			cr.Vars = MergeVars(cr.Vars, vars)
 		}
 	}
 }
@@ -519,6 +662,31 @@ func (c *ConfigYAML) ParseImportsYAML() ([]Import, []ResourceErrors[Import]) {
 		re := ResourceErrors[Import]{Resource: imp, Errors: []error{}}
 		importErrors = append(importErrors, re)
 
+		// Namespaced import:
+		// - path: lib/db.yaml
+		//   as: db
+		if c.Import.Content[i].Kind == yaml.MappingNode {
+			importYAML := ImportYAML{}
+			err := c.Import.Content[i].Decode(&importYAML)
+			if err != nil {
+				importErrors[i].Errors = append(importErrors[i].Errors, err)
+				continue
+			}
+			if importYAML.Path == "" {
+				importErrors[i].Errors = append(importErrors[i].Errors, &core.ImportPathMissing{})
+				continue
+			}
+			if importYAML.As == "" || strings.ContainsAny(importYAML.As, " \t") {
+				importErrors[i].Errors = append(importErrors[i].Errors, &core.ImportNamespaceInvalid{Name: importYAML.As})
+				continue
+			}
+
+			imp.Path = importYAML.Path
+			imp.As = importYAML.As
+			imports = append(imports, *imp)
+			continue
+		}
+
 		err := CheckIsScalarNode(*c.Import.Content[i])
 		if err != nil {
 			importErrors[i].Errors = append(importErrors[i].Errors, err)
@@ -531,13 +699,32 @@ func (c *ConfigYAML) ParseImportsYAML() ([]Import, []ResourceErrors[Import]) {
 	return imports, importErrors
 }
 
+// resolveImport returns the absolute path of an import, git imports are resolved to
+// their checkout in the import cache.
+func (cr *ConfigResources) resolveImport(dir string, path string) (string, error) {
+	g, ok := parseGitImport(dir, path)
+	if !ok {
+		return core.GetAbsolutePath(dir, path, "")
+	}
+
+	if cr.Lock == nil {
+		lock, err := LoadImportLock(cr.LockPath)
+		if err != nil {
+			return "", err
+		}
+		cr.Lock = lock
+	}
+
+	return cr.Lock.Resolve(*g)
+}
+
 func dfsImport(n *Node, m map[string]*Node, cycles *[]NodeLink, cr *ConfigResources) {
 	n.Visiting = true
 
 	for i := range n.Imports {
-		p, err := core.GetAbsolutePath(filepath.Dir(n.Path), n.Imports[i].Path, "")
+		p, err := cr.resolveImport(filepath.Dir(n.Path), n.Imports[i].Path)
 		if err != nil {
-			importError := ResourceErrors[Import]{Resource: &n.Imports[i], Errors: core.StringsToErrors(err.(*yaml.TypeError).Errors)}
+			importError := ResourceErrors[Import]{Resource: &n.Imports[i], Errors: []error{err}}
 			cr.ImportErrors = append(cr.ImportErrors, importError)
 			continue
 		}
@@ -548,7 +735,7 @@ func dfsImport(n *Node, m map[string]*Node, cycles *[]NodeLink, cr *ConfigResour
 		if exists {
 			nc = *v
 		} else {
-			nc = Node{Path: p}
+			nc = Node{Path: p, Namespace: namespaced(n.Namespace, n.Imports[i].As)}
 			m[nc.Path] = &nc
 		}
 
@@ -599,7 +786,9 @@ func dfsImport(n *Node, m map[string]*Node, cycles *[]NodeLink, cr *ConfigResour
 		}
 
 		// Load resources from the config
+		from := cr.count()
 		configYAML.loadResources(cr)
+		cr.setNamespace(nc.Namespace, from)
 
 		dfsImport(&nc, m, cycles, cr)
 	}
@@ -762,6 +951,46 @@ func checkDuplicateObjects(config Config) string {
 		}
 	}
 
+	// Server group
+	groupIDS := []string{}
+	visitedGroups := make(map[string]bool, 0)
+	groups := make(map[string][]string, 0)
+	for _, g := range config.ServerGroups {
+		groups[g.Name] = append(groups[g.Name], g.context)
+		_, exists := visitedGroups[g.Name]
+		if !exists {
+			groupIDS = append(groupIDS, g.Name)
+			visitedGroups[g.Name] = true
+		}
+	}
+
+	for _, id := range groupIDS {
+		if len(groups[id]) > 1 {
+			err := &FoundDuplicateObjects{Name: id, Type: "server group", Values: groups[id]}
+			errString = fmt.Sprintf("%s%s\n\n", errString, err.Error())
+		}
+	}
+
+	// Secret
+	secretIDS := []string{}
+	visitedSecrets := make(map[string]bool, 0)
+	secrets := make(map[string][]string, 0)
+	for _, s := range config.Secrets {
+		secrets[s.Name] = append(secrets[s.Name], s.context)
+		_, exists := visitedSecrets[s.Name]
+		if !exists {
+			secretIDS = append(secretIDS, s.Name)
+			visitedSecrets[s.Name] = true
+		}
+	}
+
+	for _, id := range secretIDS {
+		if len(secrets[id]) > 1 {
+			err := &FoundDuplicateObjects{Name: id, Type: "secret", Values: secrets[id]}
+			errString = fmt.Sprintf("%s%s\n\n", errString, err.Error())
+		}
+	}
+
 	return errString
 }
 
@@ -0,0 +1,262 @@
+package dao
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/sake/core"
+)
+
+const (
+	GIT_IMPORT_PREFIX   = "git::"
+	IMPORT_LOCK_FILE    = "sake.lock"
+	DEFAULT_IMPORT_FILE = "sake.yaml"
+)
+
+// GitImport is an import from a git repository:
+//
+//	import:
+//	  - git::https://github.com/org/tasks.git//deploy/sake.yaml?ref=v1.2
+//	  - git::../mirrors/tasks.git?ref=main
+//	  - /srv/git/tasks.git
+//
+// The path after `//` is the file to import, it defaults to sake.yaml. The ref is a
+// branch, tag or commit, it defaults to the default branch.
+type GitImport struct {
+	Source string
+	URL    string
+	Path   string
+	Ref    string
+}
+
+// ImportLock pins git imports to a commit and the content hash of the imported file.
+// It's stored next to the main config as sake.lock.
+type ImportLock struct {
+	Imports []LockedImport `yaml:"imports"`
+
+	path    string
+	used    map[string]bool
+	changed bool
+}
+
+type LockedImport struct {
+	Source string `yaml:"source"`
+	Commit string `yaml:"commit"`
+	Hash   string `yaml:"hash"`
+}
+
+// parseGitImport returns the git import for an import entry, ok is false if the entry
+// is neither prefixed with git:: nor a path to a bare repository.
+func parseGitImport(configDir string, source string) (*GitImport, bool) {
+	rest, prefixed := strings.CutPrefix(source, GIT_IMPORT_PREFIX)
+
+	rest, query, _ := strings.Cut(rest, "?")
+	ref := "HEAD"
+	if values, err := url.ParseQuery(query); err == nil && values.Get("ref") != "" {
+		ref = values.Get("ref")
+	}
+
+	// The path separator `//` comes after the scheme separator `://`
+	start := 0
+	if i := strings.Index(rest, "://"); i >= 0 {
+		start = i + 3
+	}
+	repo, path := rest, ""
+	if i := strings.Index(rest[start:], "//"); i >= 0 {
+		repo, path = rest[:start+i], rest[start+i+2:]
+	}
+	if path == "" {
+		path = DEFAULT_IMPORT_FILE
+	}
+
+	if isLocalRepo(repo) {
+		p, err := core.GetAbsolutePath(configDir, repo, "")
+		if err != nil {
+			return nil, false
+		}
+		repo = p
+	}
+
+	if !prefixed && !isBareRepo(repo) {
+		return nil, false
+	}
+
+	return &GitImport{Source: source, URL: repo, Path: path, Ref: ref}, true
+}
+
+// isLocalRepo returns false for URLs, such as https://host/repo.git and git@host:repo.git.
+func isLocalRepo(repo string) bool {
+	if strings.Contains(repo, "://") {
+		return false
+	}
+
+	colon := strings.Index(repo, ":")
+	slash := strings.Index(repo, "/")
+	return colon < 0 || (slash >= 0 && slash < colon)
+}
+
+func isBareRepo(dir string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LoadImportLock reads the lockfile, a missing lockfile is treated as empty.
+func LoadImportLock(path string) (*ImportLock, error) {
+	lock := &ImportLock{path: path, used: make(map[string]bool)}
+
+	dat, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return lock, err
+	}
+
+	if err := yaml.Unmarshal(dat, lock); err != nil {
+		return lock, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return lock, nil
+}
+
+// Save writes the lockfile if imports were added or are no longer used.
+func (l *ImportLock) Save() error {
+	imports := []LockedImport{}
+	for _, imp := range l.Imports {
+		if l.used[imp.Source] {
+			imports = append(imports, imp)
+		}
+	}
+
+	if !l.changed && len(imports) == len(l.Imports) {
+		return nil
+	}
+	l.Imports = imports
+
+	dat, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	header := "# Generated by sake, pins git imports to a commit. Remove an entry to update it.\n"
+	return os.WriteFile(l.path, append([]byte(header), dat...), 0644)
+}
+
+func (l *ImportLock) get(source string) (LockedImport, bool) {
+	for _, imp := range l.Imports {
+		if imp.Source == source {
+			return imp, true
+		}
+	}
+
+	return LockedImport{}, false
+}
+
+// Resolve returns the path of the imported file in the local cache. The repository is only
+// fetched if the import isn't locked yet, or the locked commit isn't in the cache.
+func (l *ImportLock) Resolve(g GitImport) (string, error) {
+	sum := sha256.Sum256([]byte(g.URL))
+	base := filepath.Join(cacheDir("imports"), hex.EncodeToString(sum[:])[:16])
+	mirror := filepath.Join(base, "mirror.git")
+
+	locked, isLocked := l.get(g.Source)
+	commit := locked.Commit
+	if !isLocked {
+		if err := fetchMirror(g.URL, mirror); err != nil {
+			return "", &core.ImportGitFailed{Source: g.Source, Err: err.Error()}
+		}
+
+		out, err := runGit("--git-dir", mirror, "rev-parse", "--verify", "--quiet", g.Ref+"^{commit}")
+		if err != nil {
+			return "", &core.ImportGitFailed{Source: g.Source, Err: fmt.Sprintf("cannot find ref `%s`", g.Ref)}
+		}
+		commit = out
+	}
+
+	checkout := filepath.Join(base, commit)
+	if _, err := os.Stat(checkout); err != nil {
+		if err := checkoutCommit(g.URL, mirror, commit, checkout); err != nil {
+			return "", &core.ImportGitFailed{Source: g.Source, Err: err.Error()}
+		}
+	}
+
+	file := filepath.Join(checkout, g.Path)
+	dat, err := os.ReadFile(file)
+	if err != nil {
+		return "", &core.ImportGitFailed{Source: g.Source, Err: fmt.Sprintf("cannot find `%s` in commit %s", g.Path, commit)}
+	}
+	fileSum := sha256.Sum256(dat)
+	hash := "sha256:" + hex.EncodeToString(fileSum[:])
+
+	if isLocked && locked.Hash != hash {
+		return "", &core.ImportHashMismatch{Source: g.Source, Wanted: locked.Hash, Found: hash}
+	}
+
+	if !isLocked {
+		l.Imports = append(l.Imports, LockedImport{Source: g.Source, Commit: commit, Hash: hash})
+		l.changed = true
+	}
+	l.used[g.Source] = true
+
+	return file, nil
+}
+
+func fetchMirror(url string, mirror string) error {
+	if _, err := os.Stat(mirror); err == nil {
+		_, err := runGit("--git-dir", mirror, "fetch", "--quiet", "--prune", "--tags", "origin")
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(mirror), 0755); err != nil {
+		return err
+	}
+
+	_, err := runGit("clone", "--quiet", "--mirror", url, mirror)
+	return err
+}
+
+// checkoutCommit checks out the commit from the mirror, fetching it first if it's missing.
+func checkoutCommit(url string, mirror string, commit string, checkout string) error {
+	if _, err := runGit("--git-dir", mirror, "cat-file", "-e", commit+"^{commit}"); err != nil {
+		if err := fetchMirror(url, mirror); err != nil {
+			return err
+		}
+	}
+
+	tmp := checkout + ".tmp"
+	_ = os.RemoveAll(tmp)
+	if _, err := runGit("clone", "--quiet", "--no-checkout", mirror, tmp); err != nil {
+		return err
+	}
+	if _, err := runGit("-C", tmp, "checkout", "--quiet", "--detach", commit); err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, checkout)
+}
+
+func runGit(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", errors.New(strings.TrimSpace(string(out)))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
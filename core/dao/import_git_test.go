@@ -0,0 +1,89 @@
+package dao
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/alajmo/sake/core"
+	"github.com/alajmo/sake/core/test"
+)
+
+func TestParseGitImport(t *testing.T) {
+	g, ok := parseGitImport("/config", "git::https://github.com/org/tasks.git//deploy/sake.yaml?ref=v1.2")
+	if !ok {
+		t.Fatal("expected git import")
+	}
+	test.CheckEqS(t, g.URL, "https://github.com/org/tasks.git")
+	test.CheckEqS(t, g.Path, "deploy/sake.yaml")
+	test.CheckEqS(t, g.Ref, "v1.2")
+
+	g, ok = parseGitImport("/config", "git::../mirrors/tasks.git")
+	if !ok {
+		t.Fatal("expected git import")
+	}
+	test.CheckEqS(t, g.URL, "/mirrors/tasks.git")
+	test.CheckEqS(t, g.Path, DEFAULT_IMPORT_FILE)
+	test.CheckEqS(t, g.Ref, "HEAD")
+
+	if _, ok := parseGitImport("/config", "lib/tasks.yaml"); ok {
+		t.Fatal("expected file import")
+	}
+}
+
+func TestResolveGitImport(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("SAKE_CACHE_DIR", filepath.Join(dir, "cache"))
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@a", "GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@a")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+
+	src := filepath.Join(dir, "src")
+	git("init", "--quiet", src)
+	err := os.WriteFile(filepath.Join(src, "sake.yaml"), []byte("tasks:\n  ping: echo pong\n"), 0644)
+	test.CheckErr(t, err)
+	git("-C", src, "add", "sake.yaml")
+	git("-C", src, "commit", "--quiet", "-m", "init")
+	git("clone", "--quiet", "--bare", src, filepath.Join(dir, "tasks.git"))
+
+	lockPath := filepath.Join(dir, IMPORT_LOCK_FILE)
+	lock, err := LoadImportLock(lockPath)
+	test.CheckErr(t, err)
+
+	// A plain path to a bare repository is a git import
+	g, ok := parseGitImport(dir, "tasks.git")
+	if !ok {
+		t.Fatal("expected git import")
+	}
+	file, err := lock.Resolve(*g)
+	test.CheckErr(t, err)
+	test.CheckErr(t, lock.Save())
+
+	// Locked imports are resolved without the repository
+	err = os.RemoveAll(filepath.Join(dir, "tasks.git"))
+	test.CheckErr(t, err)
+
+	lock, err = LoadImportLock(lockPath)
+	test.CheckErr(t, err)
+	test.CheckEqN(t, len(lock.Imports), 1)
+	locked, err := lock.Resolve(*g)
+	test.CheckErr(t, err)
+	test.CheckEqS(t, locked, file)
+
+	// Changed content is detected
+	lock.Imports[0].Hash = "sha256:0"
+	_, err = lock.Resolve(*g)
+	if _, ok := err.(*core.ImportHashMismatch); !ok {
+		t.Fatalf("expected hash mismatch, got %v", err)
+	}
+}
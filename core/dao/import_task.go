@@ -71,31 +71,51 @@ func dfsTask(task *Task, tn *TaskNode, tm map[string]*TaskNode, cycles *[]TaskLi
 				tty = *tn.TaskRefs[i].TTY
 			}
 
+			pty := task.PTY
+			if tn.TaskRefs[i].PTY != nil {
+				pty = *tn.TaskRefs[i].PTY
+			}
+
+			cmdTemplate := task.CmdTemplate
+			if tn.TaskRefs[i].CmdTemplate != nil {
+				cmdTemplate = *tn.TaskRefs[i].CmdTemplate
+			}
+
+			localEnvClear := task.LocalEnvClear
+			if tn.TaskRefs[i].LocalEnvClear != nil {
+				localEnvClear = *tn.TaskRefs[i].LocalEnvClear
+			}
+
 			ignoreErrors := task.Spec.IgnoreErrors
 			if tn.TaskRefs[i].IgnoreErrors != nil {
 				ignoreErrors = *tn.TaskRefs[i].IgnoreErrors
 			}
 
 			envs := MergeEnvs(tn.TaskRefs[i].Envs, task.Envs)
+			vars := MergeVars(tn.TaskRefs[i].Vars, task.Vars)
 
 			workDir := SelectFirstNonEmpty(tn.TaskRefs[i].WorkDir, task.WorkDir)
 			shell := SelectFirstNonEmpty(tn.TaskRefs[i].Shell, task.Shell)
 
 			childTask := TaskCmd{
-				ID:           tn.TaskRefs[i].Task,
-				Name:         tn.TaskRefs[i].Name,
-				Desc:         tn.TaskRefs[i].Desc,
-				Register:     tn.TaskRefs[i].Register,
-				RootDir:      filepath.Dir(task.context),
-				WorkDir:      workDir,
-				Shell:        shell,
-				Cmd:          tn.TaskRefs[i].Cmd,
-				Envs:         envs,
-				Local:        local,
-				TTY:          tty,
-				IgnoreErrors: ignoreErrors,
+				ID:            tn.TaskRefs[i].Task,
+				Name:          tn.TaskRefs[i].Name,
+				Desc:          tn.TaskRefs[i].Desc,
+				Register:      tn.TaskRefs[i].Register,
+				RootDir:       filepath.Dir(task.context),
+				WorkDir:       workDir,
+				Shell:         shell,
+				Cmd:           tn.TaskRefs[i].Cmd,
+				CmdTemplate:   cmdTemplate,
+				Envs:          envs,
+				Vars:          vars,
+				Local:         local,
+				TTY:           tty,
+				PTY:           pty,
+				LocalEnvClear: localEnvClear,
+				IgnoreErrors:  ignoreErrors,
 			}
-			task.Tasks = append(task.Tasks, childTask)
+			task.Tasks = append(task.Tasks, expandLoop(childTask, tn.TaskRefs[i].Loop)...)
 		} else {
 			// Reference command
 			// tasks:
@@ -113,6 +133,10 @@ func dfsTask(task *Task, tn *TaskNode, tm map[string]*TaskNode, cycles *[]TaskLi
 				continue
 			}
 
+			// Params of referenced tasks are resolved when the task is run
+			task.addParams(childTask.Params)
+			task.addSecretEnvs(childTask.SecretEnvs)
+
 			if childTask.Cmd != "" {
 				// tasks:
 				//   a:
@@ -141,32 +165,52 @@ func dfsTask(task *Task, tn *TaskNode, tm map[string]*TaskNode, cycles *[]TaskLi
 					tty = *tn.TaskRefs[i].TTY
 				}
 
+				pty := childTask.PTY
+				if tn.TaskRefs[i].PTY != nil {
+					pty = *tn.TaskRefs[i].PTY
+				}
+
+				cmdTemplate := childTask.CmdTemplate
+				if tn.TaskRefs[i].CmdTemplate != nil {
+					cmdTemplate = *tn.TaskRefs[i].CmdTemplate
+				}
+
+				localEnvClear := childTask.LocalEnvClear
+				if tn.TaskRefs[i].LocalEnvClear != nil {
+					localEnvClear = *tn.TaskRefs[i].LocalEnvClear
+				}
+
 				ignoreErrors := childTask.Spec.IgnoreErrors
 				if tn.TaskRefs[i].IgnoreErrors != nil {
 					ignoreErrors = *tn.TaskRefs[i].IgnoreErrors
 				}
 
 				envs := MergeEnvs(tn.TaskRefs[i].Envs, task.Envs, childTask.Envs)
+				vars := MergeVars(tn.TaskRefs[i].Vars, task.Vars, childTask.Vars)
 
 				workDir := SelectFirstNonEmpty(tn.TaskRefs[i].WorkDir, task.WorkDir, childTask.WorkDir)
 				shell := SelectFirstNonEmpty(tn.TaskRefs[i].Shell, task.Shell, childTask.Shell)
 
 				// TODO: Should task.Register be set here?
 				t := TaskCmd{
-					ID:           childTask.ID,
-					Name:         name,
-					Desc:         desc,
-					RootDir:      filepath.Dir(task.context),
-					WorkDir:      workDir,
-					Shell:        shell,
-					Cmd:          childTask.Cmd,
-					Register:     tn.TaskRefs[i].Register,
-					Envs:         envs,
-					Local:        local,
-					TTY:          tty,
-					IgnoreErrors: ignoreErrors,
+					ID:            childTask.ID,
+					Name:          name,
+					Desc:          desc,
+					RootDir:       filepath.Dir(task.context),
+					WorkDir:       workDir,
+					Shell:         shell,
+					Cmd:           childTask.Cmd,
+					CmdTemplate:   cmdTemplate,
+					Register:      tn.TaskRefs[i].Register,
+					Envs:          envs,
+					Vars:          vars,
+					Local:         local,
+					TTY:           tty,
+					PTY:           pty,
+					LocalEnvClear: localEnvClear,
+					IgnoreErrors:  ignoreErrors,
 				}
-				task.Tasks = append(task.Tasks, t)
+				task.Tasks = append(task.Tasks, expandLoop(t, tn.TaskRefs[i].Loop)...)
 			} else {
 				// tasks:
 				//   a:
@@ -201,6 +245,11 @@ func dfsTask(task *Task, tn *TaskNode, tm map[string]*TaskNode, cycles *[]TaskLi
 					// TODO: May need to add IgnoreErrors here
 					tnn.TaskRefs = append(tnn.TaskRefs, k)
 					tnn.TaskRefs[j].Envs = MergeEnvs(tn.TaskRefs[i].Envs, tnn.TaskRefs[j].Envs, childTask.Envs)
+					tnn.TaskRefs[j].Vars = MergeVars(tn.TaskRefs[i].Vars, tnn.TaskRefs[j].Vars, childTask.Vars)
+					// A loop on the reference applies to each command of the referenced task
+					if tnn.TaskRefs[j].Loop == nil {
+						tnn.TaskRefs[j].Loop = tn.TaskRefs[i].Loop
+					}
 					tnn.TaskRefs[j].WorkDir = SelectFirstNonEmpty(tn.TaskRefs[i].WorkDir, tnn.TaskRefs[j].WorkDir, childTask.WorkDir)
 					tnn.TaskRefs[j].Shell = SelectFirstNonEmpty(tn.TaskRefs[i].Shell, tnn.TaskRefs[j].Shell, childTask.Shell)
 				}
@@ -0,0 +1,186 @@
+package dao
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/alajmo/sake/core"
+)
+
+// InventoryHost is a host returned by an inventory command. Inventory commands either print
+// hosts separated by whitespace, or a JSON list of hosts:
+//
+//	[
+//	  {"name": "web-1", "host": "10.0.0.1", "user": "admin", "tags": ["web"], "env": {"ZONE": "a"}},
+//	  {"name": "db-1", "host": "10.0.1.1", "port": 2222, "bastion": "jump@10.0.0.100", "identity_file": "~/.ssh/db"}
+//	]
+type InventoryHost struct {
+	Name    string         `json:"name"`
+	Host    string         `json:"host"`
+	User    string         `json:"user"`
+	Port    uint16         `json:"port"`
+	Tags    []string       `json:"tags"`
+	Env     map[string]any `json:"env"`
+	Bastion string         `json:"bastion"`
+	Desc    string         `json:"desc"`
+
+	// Bastions are already resolved bastions, used instead of Bastion, for instance ProxyJump
+	// hosts of the ssh config. A bastion without a user defaults to the user of the host.
+	Bastions []Bastion `json:"-"`
+
+	IdentityFile string `json:"identity_file"`
+}
+
+// ParseInventoryOutput parses the output of an inventory command, output starting with [ is
+// parsed as JSON.
+func ParseInventoryOutput(name string, out string) ([]InventoryHost, error) {
+	if !strings.HasPrefix(out, "[") {
+		hosts := []InventoryHost{}
+		for _, host := range strings.Fields(out) {
+			hosts = append(hosts, InventoryHost{Host: host})
+		}
+		return hosts, nil
+	}
+
+	var hosts []InventoryHost
+	if err := json.Unmarshal([]byte(out), &hosts); err != nil {
+		return nil, &core.InventoryInvalidJSON{Name: name, Err: err.Error()}
+	}
+
+	for i, host := range hosts {
+		if host.Host == "" {
+			return nil, &core.InventoryHostMissing{Name: name, Index: i}
+		}
+	}
+
+	return hosts, nil
+}
+
+// CreateInventoryServer creates the i:th server of an inventory. Fields that the host sets
+// take precedence over the fields of the inventory server, tags are combined.
+func CreateInventoryServer(h InventoryHost, i int, server Server, userArgs []string) (Server, error) {
+	defaultUser := server.User
+	if h.User != "" {
+		defaultUser = h.User
+	}
+	defaultPort := server.Port
+	if h.Port != 0 {
+		defaultPort = h.Port
+	}
+
+	user, host, port, err := core.ParseHostName(h.Host, defaultUser, defaultPort)
+	if err != nil {
+		return server, err
+	}
+
+	name := h.Name
+	if name == "" {
+		name = fmt.Sprintf("%s-%d", server.Name, i)
+	}
+
+	desc := server.Desc
+	if h.Desc != "" {
+		desc = h.Desc
+	}
+
+	tags := slices.Clone(server.Tags)
+	for _, tag := range h.Tags {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	// Multiple bastions are separated by comma, like ProxyJump in ssh config
+	bastions := server.Bastions
+	if h.Bastion != "" {
+		bastions = []Bastion{}
+		for _, b := range strings.Split(h.Bastion, ",") {
+			bUser, bHost, bPort, err := core.ParseHostName(strings.TrimSpace(b), defaultUser, defaultPort)
+			if err != nil {
+				return server, err
+			}
+			bastions = append(bastions, Bastion{User: bUser, Host: bHost, Port: bPort})
+		}
+	}
+	if len(h.Bastions) > 0 {
+		bastions = []Bastion{}
+		for _, b := range h.Bastions {
+			if b.User == "" {
+				b.User = defaultUser
+			}
+			bastions = append(bastions, b)
+		}
+	}
+
+	identityFile, pubFile := server.IdentityFile, server.PubFile
+	if h.IdentityFile != "" {
+		iFile, err := core.ExpandPath(os.ExpandEnv(h.IdentityFile))
+		if err != nil {
+			return server, err
+		}
+		identityFile = &iFile
+		pubFile = nil
+		if _, err := os.Stat(iFile + ".pub"); err == nil {
+			pFile := iFile + ".pub"
+			pubFile = &pFile
+		}
+	}
+
+	hostEnvs := []string{}
+	keys := make([]string, 0, len(h.Env))
+	for key := range h.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		hostEnvs = append(hostEnvs, fmt.Sprintf("%s=%v", key, h.Env[key]))
+	}
+
+	serverEnvs := MergeEnvs(hostEnvs, server.Envs)
+	serverEnvs = append(serverEnvs, []string{
+		fmt.Sprintf("S_HOST=%s", host),
+		fmt.Sprintf("S_USER=%s", user),
+		fmt.Sprintf("S_PORT=%d", port),
+	}...)
+	if len(h.Tags) > 0 {
+		serverEnvs = MergeEnvs([]string{fmt.Sprintf("S_TAGS=%s", strings.Join(tags, ","))}, serverEnvs)
+	}
+	if h.Bastion != "" || len(h.Bastions) > 0 {
+		serverEnvs = MergeEnvs([]string{fmt.Sprintf("S_BASTION=%s", getBastionHosts(bastions, ","))}, serverEnvs)
+	}
+	serverEnvs = append(serverEnvs, userArgs...)
+
+	iServer := &Server{
+		Name:         name,
+		Group:        server.Group,
+		Extends:      server.Extends,
+		Desc:         desc,
+		Host:         host,
+		User:         user,
+		Port:         port,
+		Local:        server.Local,
+		Connection:   server.Connection,
+		Container:    server.Container,
+		Namespace:    server.Namespace,
+		Tags:         tags,
+		Shell:        server.Shell,
+		WorkDir:      server.WorkDir,
+		Envs:         serverEnvs,
+		SecretEnvs:   server.SecretEnvs,
+		Vars:         server.Vars,
+		Bastions:     bastions,
+		IdentityFile: identityFile,
+		PubFile:      pubFile,
+		Password:     server.Password,
+
+		RootDir:     server.RootDir,
+		context:     server.context,
+		contextLine: server.contextLine,
+	}
+
+	return *iServer, nil
+}
@@ -0,0 +1,380 @@
+package dao
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/sake/core"
+)
+
+const InventoryFormatAnsible = "ansible"
+
+// Ansible variables that map to host fields instead of env variables.
+var ansibleHostVars = map[string]string{
+	"ansible_host":     "host",
+	"ansible_ssh_host": "host",
+	"ansible_user":     "user",
+	"ansible_ssh_user": "user",
+	"ansible_port":     "port",
+	"ansible_ssh_port": "port",
+}
+
+type ansibleGroup struct {
+	hosts    []string
+	children []string
+	vars     map[string]any
+}
+
+type ansibleInventory struct {
+	groups   map[string]*ansibleGroup
+	hosts    []string // in order of appearance
+	hostVars map[string]map[string]any
+}
+
+func newAnsibleInventory() *ansibleInventory {
+	return &ansibleInventory{
+		groups:   map[string]*ansibleGroup{},
+		hostVars: map[string]map[string]any{},
+	}
+}
+
+func (inv *ansibleInventory) group(name string) *ansibleGroup {
+	g, ok := inv.groups[name]
+	if !ok {
+		g = &ansibleGroup{vars: map[string]any{}}
+		inv.groups[name] = g
+	}
+	return g
+}
+
+func (inv *ansibleInventory) addHost(group string, host string, vars map[string]any) {
+	g := inv.group(group)
+	if !slices.Contains(g.hosts, host) {
+		g.hosts = append(g.hosts, host)
+	}
+
+	if _, ok := inv.hostVars[host]; !ok {
+		inv.hosts = append(inv.hosts, host)
+		inv.hostVars[host] = map[string]any{}
+	}
+	for k, v := range vars {
+		inv.hostVars[host][k] = v
+	}
+}
+
+func (inv *ansibleInventory) addChild(group string, child string) {
+	g := inv.group(group)
+	inv.group(child)
+	if !slices.Contains(g.children, child) {
+		g.children = append(g.children, child)
+	}
+}
+
+// ParseAnsibleInventory parses an Ansible inventory in INI, YAML or JSON (ansible-inventory --list)
+// format. Groups, including parent groups, become tags and vars become env variables, with host
+// vars taking precedence over vars of child groups, which take precedence over vars of parent groups.
+func ParseAnsibleInventory(name string, out string) ([]InventoryHost, error) {
+	var inv *ansibleInventory
+	var err error
+
+	var node yaml.Node
+	if yaml.Unmarshal([]byte(out), &node) == nil && len(node.Content) > 0 && node.Content[0].Kind == yaml.MappingNode {
+		inv, err = parseAnsibleYAML(node.Content[0])
+	} else {
+		inv, err = parseAnsibleINI(out)
+	}
+	if err != nil {
+		return nil, &core.InventoryInvalidAnsible{Name: name, Err: err.Error()}
+	}
+
+	hosts, err := inv.inventoryHosts()
+	if err != nil {
+		return nil, &core.InventoryInvalidAnsible{Name: name, Err: err.Error()}
+	}
+
+	return hosts, nil
+}
+
+func parseAnsibleINI(out string) (*ansibleInventory, error) {
+	inv := newAnsibleInventory()
+	section, kind := "ungrouped", "hosts"
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	line := 0
+	for scanner.Scan() {
+		line += 1
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+
+		// [group], [group:children] or [group:vars]
+		if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+			section, kind, _ = strings.Cut(text[1:len(text)-1], ":")
+			switch kind {
+			case "":
+				kind = "hosts"
+			case "children", "vars":
+			default:
+				return nil, fmt.Errorf("line %d: invalid section `%s`", line, text)
+			}
+			inv.group(section)
+			continue
+		}
+
+		switch kind {
+		case "children":
+			inv.addChild(section, text)
+		case "vars":
+			key, value, found := strings.Cut(text, "=")
+			if !found {
+				return nil, fmt.Errorf("line %d: expected key=value, found `%s`", line, text)
+			}
+			inv.group(section).vars[strings.TrimSpace(key)] = unquote(strings.TrimSpace(value))
+		default:
+			fields := strings.Fields(text)
+			vars := map[string]any{}
+			for _, field := range fields[1:] {
+				key, value, found := strings.Cut(field, "=")
+				if !found {
+					return nil, fmt.Errorf("line %d: expected key=value, found `%s`", line, field)
+				}
+				vars[key] = unquote(value)
+			}
+
+			hosts, err := expandAnsibleHost(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			for _, host := range hosts {
+				inv.addHost(section, host, vars)
+			}
+		}
+	}
+
+	return inv, scanner.Err()
+}
+
+// parseAnsibleYAML parses YAML inventories, where hosts and children are mappings, and the JSON
+// output of ansible-inventory --list, where they are lists and host vars are stored in _meta.
+func parseAnsibleYAML(root *yaml.Node) (*ansibleInventory, error) {
+	inv := newAnsibleInventory()
+
+	var parseGroup func(name string, node *yaml.Node) error
+	parseGroup = func(name string, node *yaml.Node) error {
+		inv.group(name)
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			switch key {
+			case "hosts":
+				if value.Kind == yaml.SequenceNode {
+					for _, h := range value.Content {
+						inv.addHost(name, h.Value, nil)
+					}
+					continue
+				}
+				for j := 0; j+1 < len(value.Content); j += 2 {
+					vars := map[string]any{}
+					if err := value.Content[j+1].Decode(&vars); err != nil {
+						return fmt.Errorf("line %d: %w", value.Content[j+1].Line, err)
+					}
+					hosts, err := expandAnsibleHost(value.Content[j].Value)
+					if err != nil {
+						return fmt.Errorf("line %d: %w", value.Content[j].Line, err)
+					}
+					for _, host := range hosts {
+						inv.addHost(name, host, vars)
+					}
+				}
+			case "children":
+				if value.Kind == yaml.SequenceNode {
+					for _, c := range value.Content {
+						inv.addChild(name, c.Value)
+					}
+					continue
+				}
+				for j := 0; j+1 < len(value.Content); j += 2 {
+					inv.addChild(name, value.Content[j].Value)
+					if err := parseGroup(value.Content[j].Value, value.Content[j+1]); err != nil {
+						return err
+					}
+				}
+			case "vars":
+				if err := value.Decode(&inv.group(name).vars); err != nil {
+					return fmt.Errorf("line %d: %w", value.Line, err)
+				}
+			}
+		}
+
+		return nil
+	}
+
+	var meta *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "_meta" {
+			meta = root.Content[i+1]
+			continue
+		}
+		if err := parseGroup(root.Content[i].Value, root.Content[i+1]); err != nil {
+			return nil, err
+		}
+	}
+
+	if hostVars := mappingValue(meta, "hostvars"); hostVars != nil {
+		for i := 0; i+1 < len(hostVars.Content); i += 2 {
+			vars := map[string]any{}
+			if err := hostVars.Content[i+1].Decode(&vars); err != nil {
+				return nil, fmt.Errorf("line %d: %w", hostVars.Content[i+1].Line, err)
+			}
+			host := hostVars.Content[i].Value
+			if _, ok := inv.hostVars[host]; ok {
+				for k, v := range vars {
+					inv.hostVars[host][k] = v
+				}
+			}
+		}
+	}
+
+	return inv, nil
+}
+
+// expandAnsibleHost expands host ranges, such as web[01:10].example.com.
+func expandAnsibleHost(host string) ([]string, error) {
+	if !strings.Contains(host, "[") {
+		return []string{host}, nil
+	}
+
+	return core.EvaluateRange(host)
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+func (inv *ansibleInventory) inventoryHosts() ([]InventoryHost, error) {
+	parents := map[string][]string{}
+	for name, g := range inv.groups {
+		for _, child := range g.children {
+			parents[child] = append(parents[child], name)
+		}
+	}
+
+	// Depth of a group is the length of the longest path from a top group
+	depths := map[string]int{}
+	var depth func(name string, visiting map[string]bool) int
+	depth = func(name string, visiting map[string]bool) int {
+		if d, ok := depths[name]; ok {
+			return d
+		}
+		if visiting[name] {
+			return 0
+		}
+		visiting[name] = true
+		d := 0
+		for _, p := range parents[name] {
+			d = max(d, depth(p, visiting)+1)
+		}
+		depths[name] = d
+		return d
+	}
+
+	hosts := []InventoryHost{}
+	for _, name := range inv.hosts {
+		// Groups of the host, including parent groups
+		groups := []string{"all"}
+		queue := []string{}
+		for g, group := range inv.groups {
+			if slices.Contains(group.hosts, name) {
+				queue = append(queue, g)
+			}
+		}
+		for len(queue) > 0 {
+			g := queue[0]
+			queue = queue[1:]
+			if slices.Contains(groups, g) {
+				continue
+			}
+			groups = append(groups, g)
+			queue = append(queue, parents[g]...)
+		}
+		sort.SliceStable(groups, func(i, j int) bool {
+			di, dj := depth(groups[i], map[string]bool{}), depth(groups[j], map[string]bool{})
+			if groups[i] == "all" || groups[j] == "all" {
+				return groups[i] == "all" && groups[j] != "all"
+			}
+			if di != dj {
+				return di < dj
+			}
+			return groups[i] < groups[j]
+		})
+
+		vars := map[string]any{}
+		tags := []string{}
+		for _, g := range groups {
+			if group, ok := inv.groups[g]; ok {
+				for k, v := range group.vars {
+					vars[k] = v
+				}
+			}
+			if g != "all" && g != "ungrouped" {
+				tags = append(tags, g)
+			}
+		}
+		for k, v := range inv.hostVars[name] {
+			vars[k] = v
+		}
+
+		h := InventoryHost{Name: name, Host: name, Tags: tags, Env: map[string]any{}}
+		for k, v := range vars {
+			field, ok := ansibleHostVars[k]
+			if !ok {
+				h.Env[k] = ansibleEnvValue(v)
+				continue
+			}
+
+			value := fmt.Sprintf("%v", v)
+			switch field {
+			case "host":
+				h.Host = value
+			case "user":
+				h.User = value
+			case "port":
+				port, err := strconv.ParseUint(value, 10, 16)
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s `%s` for host %s", k, value, name)
+				}
+				h.Port = uint16(port)
+			}
+		}
+
+		hosts = append(hosts, h)
+	}
+
+	return hosts, nil
+}
+
+// ansibleEnvValue formats lists and mappings as JSON.
+func ansibleEnvValue(v any) any {
+	switch v.(type) {
+	case []any, map[string]any:
+		b, err := json.Marshal(v)
+		if err == nil {
+			return string(b)
+		}
+	}
+	return v
+}
@@ -0,0 +1,77 @@
+package dao
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alajmo/sake/core"
+)
+
+// inventoryCache is the output of an inventory command, stored in
+// $SAKE_CACHE_DIR/inventory/<key>.json.
+type inventoryCache struct {
+	Created time.Time `json:"created"`
+	Output  string    `json:"output"`
+}
+
+// cacheDir returns $SAKE_CACHE_DIR/<name>, defaulting to the sake directory in the user cache directory.
+func cacheDir(name string) string {
+	if dir := os.Getenv("SAKE_CACHE_DIR"); dir != "" {
+		return filepath.Join(dir, name)
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "sake", name)
+}
+
+// inventoryCachePath returns the cache file of an inventory command, which depends on the
+// command, where it's run and its env variables.
+func inventoryCachePath(shell string, s Server, userArgs []string) string {
+	parts := []string{shell, filepath.Dir(s.context), s.Inventory}
+	parts = append(parts, s.Envs...)
+	parts = append(parts, userArgs...)
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+
+	return filepath.Join(cacheDir("inventory"), hex.EncodeToString(sum[:])+".json")
+}
+
+// runInventory runs the inventory command of the server. If the server has an inventory_cache_ttl,
+// the output is read from the cache until it expires, unless refresh is set.
+func runInventory(shell string, s Server, userArgs []string, refresh bool) (string, error) {
+	if s.InventoryCacheTTL == 0 {
+		return core.RunInventory(shell, s.context, s.Inventory, s.Envs, userArgs)
+	}
+
+	path := inventoryCachePath(shell, s, userArgs)
+	if !refresh {
+		var cache inventoryCache
+		if dat, err := os.ReadFile(path); err == nil && json.Unmarshal(dat, &cache) == nil {
+			if time.Since(cache.Created) < s.InventoryCacheTTL {
+				return cache.Output, nil
+			}
+		}
+	}
+
+	out, err := core.RunInventory(shell, s.context, s.Inventory, s.Envs, userArgs)
+	if err != nil {
+		return "", err
+	}
+
+	// Failing to write the cache only means the command is run again next time
+	if dat, err := json.Marshal(inventoryCache{Created: time.Now(), Output: out}); err == nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+			_ = os.WriteFile(path, dat, 0600)
+		}
+	}
+
+	return out, nil
+}
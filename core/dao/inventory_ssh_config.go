@@ -0,0 +1,137 @@
+package dao
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gobwas/glob"
+
+	"github.com/alajmo/sake/core"
+)
+
+// ParseSSHConfigInventory returns the hosts of the ssh config whose alias matches the
+// from_ssh_config patterns of the server. Patterns are separated by whitespace and follow ssh
+// config Host patterns, so web-* !web-canary matches all web hosts except web-canary.
+func ParseSSHConfigInventory(path *string, s Server) ([]InventoryHost, error) {
+	if path == nil || *path == "" {
+		return nil, &core.SSHConfigNotFound{Name: s.Name}
+	}
+
+	endpoints, err := core.ParseSSHConfigHosts(*path)
+	if err != nil {
+		return nil, err
+	}
+
+	var include, exclude []glob.Glob
+	for _, pattern := range strings.Fields(s.FromSSHConfig) {
+		negated := strings.HasPrefix(pattern, "!")
+		g, err := glob.Compile(strings.TrimPrefix(pattern, "!"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid from_ssh_config pattern `%s` for server `%s`: %w", pattern, s.Name, err)
+		}
+		if negated {
+			exclude = append(exclude, g)
+		} else {
+			include = append(include, g)
+		}
+	}
+
+	matches := func(name string) bool {
+		match := func(g glob.Glob) bool { return g.Match(name) }
+		return slices.ContainsFunc(include, match) && !slices.ContainsFunc(exclude, match)
+	}
+
+	aliases := make(map[string]core.Endpoint)
+	for _, e := range endpoints {
+		aliases[e.Name] = e
+	}
+
+	hosts := []InventoryHost{}
+	for _, e := range endpoints {
+		if !matches(e.Name) {
+			continue
+		}
+
+		h := InventoryHost{Name: e.Name, Host: e.HostName, User: e.User, Tags: slices.Clone(e.Tags)}
+
+		if e.Port != "" {
+			port, err := strconv.ParseUint(e.Port, 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Port `%s` for host %s in %s", e.Port, e.Name, *path)
+			}
+			h.Port = uint16(port)
+		}
+
+		if s.FromSSHConfigTags != nil {
+			for _, tag := range s.FromSSHConfigTags.FindStringSubmatch(e.Name) {
+				if tag != "" && tag != e.Name && !slices.Contains(h.Tags, tag) {
+					h.Tags = append(h.Tags, tag)
+				}
+			}
+		}
+
+		if e.ProxyJump != "" && e.ProxyJump != "none" {
+			for _, jump := range strings.Split(e.ProxyJump, ",") {
+				bastion, err := resolveProxyJump(strings.TrimSpace(jump), aliases)
+				if err != nil {
+					return nil, fmt.Errorf("host %s in %s: %w", e.Name, *path, err)
+				}
+				h.Bastions = append(h.Bastions, bastion)
+			}
+		}
+
+		if len(e.IdentityFiles) > 0 {
+			h.IdentityFile = e.IdentityFiles[0]
+		}
+
+		hosts = append(hosts, h)
+	}
+
+	if len(hosts) == 0 {
+		return nil, &core.SSHConfigNoHosts{Name: s.Name, Pattern: s.FromSSHConfig}
+	}
+
+	return hosts, nil
+}
+
+// resolveProxyJump replaces a jump host alias with the user, host name and port of the alias.
+// Like ssh, jump hosts default to port 22 rather than the port of the host.
+func resolveProxyJump(jump string, aliases map[string]core.Endpoint) (Bastion, error) {
+	user, name, found := strings.Cut(jump, "@")
+	if !found {
+		user, name = "", jump
+	}
+
+	port := ""
+	if strings.HasPrefix(name, "[") {
+		// [host]:port, used for IPv6 addresses
+		if end := strings.Index(name, "]"); end != -1 {
+			name, port = name[1:end], strings.TrimPrefix(name[end+1:], ":")
+		}
+	} else if strings.Count(name, ":") == 1 {
+		name, port, _ = strings.Cut(name, ":")
+	}
+
+	if e, ok := aliases[name]; ok {
+		name = e.HostName
+		if user == "" {
+			user = e.User
+		}
+		if port == "" {
+			port = e.Port
+		}
+	}
+
+	bastion := Bastion{User: user, Host: name, Port: 22}
+	if port != "" {
+		p, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			return bastion, fmt.Errorf("invalid port `%s` for ProxyJump %s", port, jump)
+		}
+		bastion.Port = uint16(p)
+	}
+
+	return bastion, nil
+}
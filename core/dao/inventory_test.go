@@ -0,0 +1,230 @@
+package dao
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alajmo/sake/core/test"
+)
+
+func TestParseInventoryOutput(t *testing.T) {
+	hosts, err := ParseInventoryOutput("cloud", "192.168.0.1\n  user@192.168.0.2")
+	test.CheckErr(t, err)
+	test.CheckEqN(t, len(hosts), 2)
+	test.CheckEqS(t, hosts[1].Host, "user@192.168.0.2")
+
+	out := `[
+  {"name": "web-1", "host": "192.168.0.1", "user": "admin", "tags": ["web"], "env": {"ZONE": "a", "N": 1}},
+  {"host": "192.168.0.2", "port": 2222, "bastion": "192.168.0.100"}
+]`
+	hosts, err = ParseInventoryOutput("cloud", out)
+	test.CheckErr(t, err)
+	test.CheckEqN(t, len(hosts), 2)
+
+	parent := Server{Name: "cloud", User: "root", Port: 22, Tags: []string{"cloud", "web"}, Envs: []string{"ZONE=z", "A=a"}}
+
+	s, err := CreateInventoryServer(hosts[0], 0, parent, []string{})
+	test.CheckErr(t, err)
+	test.CheckEqS(t, s.Name, "web-1")
+	test.CheckEqS(t, s.User, "admin")
+	test.CheckEqN(t, int(s.Port), 22)
+	test.CheckEqualStringArr(t, s.Tags, []string{"cloud", "web"})
+	for _, env := range []string{"N=1", "ZONE=a", "A=a", "S_TAGS=cloud,web"} {
+		if !slices.Contains(s.Envs, env) {
+			t.Fatalf("Wanted: %s in %q", env, s.Envs)
+		}
+	}
+	test.CheckEqN(t, len(MergeEnvs(s.Envs)), len(s.Envs))
+
+	s, err = CreateInventoryServer(hosts[1], 1, parent, []string{})
+	test.CheckErr(t, err)
+	test.CheckEqS(t, s.Name, "cloud-1")
+	test.CheckEqS(t, s.User, "root")
+	test.CheckEqN(t, int(s.Port), 2222)
+	test.CheckEqN(t, len(s.Bastions), 1)
+	test.CheckEqN(t, int(s.Bastions[0].Port), 2222)
+
+	_, err = ParseInventoryOutput("cloud", `[{"name": "web-1"}]`)
+	test.IsError(t, err)
+
+	_, err = ParseInventoryOutput("cloud", `[{"host": 1}]`)
+	test.IsError(t, err)
+}
+
+func TestParseAnsibleInventory(t *testing.T) {
+	ini := `
+bastion ansible_host=10.0.0.100
+
+[web]
+web[01:02] ansible_user=deploy http_port=80
+
+[db]
+db1 ansible_host=10.0.1.1 ansible_port=2222
+
+[prod:children]
+web
+db
+
+[prod:vars]
+env=production
+http_port=8080
+
+[all:vars]
+region=eu
+`
+	yml := `
+all:
+  vars:
+    region: eu
+  hosts:
+    bastion:
+      ansible_host: 10.0.0.100
+  children:
+    prod:
+      vars:
+        env: production
+        http_port: 8080
+      children:
+        web:
+          hosts:
+            web[01:02]:
+              ansible_user: deploy
+              http_port: 80
+        db:
+          hosts:
+            db1: {ansible_host: 10.0.1.1, ansible_port: 2222}
+`
+	list := `{
+  "_meta": {"hostvars": {"db1": {"ansible_host": "10.0.1.1", "ansible_port": 2222}}},
+  "all": {"children": ["prod", "ungrouped"], "vars": {"region": "eu"}},
+  "prod": {"children": ["web", "db"], "vars": {"env": "production", "http_port": 8080}},
+  "web": {"hosts": ["web01", "web02"], "vars": {"ansible_user": "deploy", "http_port": 80}},
+  "db": {"hosts": ["db1"]},
+  "ungrouped": {"hosts": ["bastion"], "vars": {"ansible_host": "10.0.0.100"}}
+}`
+
+	for _, out := range []string{ini, yml, list} {
+		hosts, err := ParseAnsibleInventory("fleet", out)
+		test.CheckErr(t, err)
+		test.CheckEqN(t, len(hosts), 4)
+
+		for _, h := range hosts {
+			test.CheckEqS(t, fmt.Sprint(h.Env["region"]), "eu")
+
+			switch h.Name {
+			case "bastion":
+				test.CheckEqS(t, h.Host, "10.0.0.100")
+				test.CheckEqN(t, len(h.Tags), 0)
+			case "web02":
+				test.CheckEqS(t, h.Host, "web02")
+				test.CheckEqS(t, h.User, "deploy")
+				test.CheckEqualStringArr(t, h.Tags, []string{"prod", "web"})
+				test.CheckEqS(t, fmt.Sprint(h.Env["http_port"]), "80")
+				test.CheckEqS(t, fmt.Sprint(h.Env["env"]), "production")
+			case "db1":
+				test.CheckEqS(t, h.Host, "10.0.1.1")
+				test.CheckEqN(t, int(h.Port), 2222)
+				test.CheckEqS(t, fmt.Sprint(h.Env["http_port"]), "8080")
+			}
+		}
+	}
+
+	_, err := ParseAnsibleInventory("fleet", "[web:hosts]\nweb1")
+	test.IsError(t, err)
+}
+
+func TestRunInventoryCache(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SAKE_CACHE_DIR", dir)
+
+	s := Server{
+		Inventory:         "echo run >> runs.txt; echo 192.168.0.1",
+		InventoryCacheTTL: time.Hour,
+		context:           filepath.Join(dir, "sake.yaml"),
+	}
+
+	runs := func() int {
+		dat, err := os.ReadFile(filepath.Join(dir, "runs.txt"))
+		test.CheckErr(t, err)
+		return strings.Count(string(dat), "run")
+	}
+
+	for range 2 {
+		out, err := runInventory("sh -c", s, []string{}, false)
+		test.CheckErr(t, err)
+		test.CheckEqS(t, out, "192.168.0.1")
+	}
+	test.CheckEqN(t, runs(), 1)
+
+	_, err := runInventory("sh -c", s, []string{}, true)
+	test.CheckErr(t, err)
+	test.CheckEqN(t, runs(), 2)
+
+	// Different user arguments are cached separately
+	_, err = runInventory("sh -c", s, []string{"env=prod"}, false)
+	test.CheckErr(t, err)
+	test.CheckEqN(t, runs(), 3)
+}
+
+func TestParseSSHConfigInventory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	config := `
+Host jump
+  HostName 10.0.0.100
+  User jump
+
+Host web-eu-1
+  HostName 10.0.0.1
+  Port 2222
+  ProxyJump jump
+  # tags: frontend
+
+Host gw
+  Port 2200
+
+Host web-us-1
+  HostName 10.0.1.1
+  ProxyJump gw,ops@relay
+
+Host web-canary db-eu-1
+
+Host web-*
+  User deploy
+  # tags: web
+`
+	err := os.WriteFile(path, []byte(config), 0600)
+	test.CheckErr(t, err)
+
+	s := Server{Name: "web", FromSSHConfig: "web-* !web-canary", FromSSHConfigTags: regexp.MustCompile(`^\w+-(\w+)-\d+$`)}
+	hosts, err := ParseSSHConfigInventory(&path, s)
+	test.CheckErr(t, err)
+	test.CheckEqN(t, len(hosts), 2)
+
+	test.CheckEqS(t, hosts[0].Name, "web-eu-1")
+	test.CheckEqS(t, hosts[0].Host, "10.0.0.1")
+	test.CheckEqS(t, hosts[0].User, "deploy")
+	test.CheckEqN(t, int(hosts[0].Port), 2222)
+	test.CheckEqN(t, len(hosts[0].Bastions), 1)
+	test.CheckEqS(t, hosts[0].Bastions[0].GetPrint(), "jump@10.0.0.100:22")
+	test.CheckEqualStringArr(t, hosts[0].Tags, []string{"frontend", "web", "eu"})
+	test.CheckEqualStringArr(t, hosts[1].Tags, []string{"web", "us"})
+
+	// Jump hosts without a dot in the host name are not mistaken for IPv6 addresses
+	server, err := CreateInventoryServer(hosts[1], 1, Server{Name: "web", Port: 22}, []string{})
+	test.CheckErr(t, err)
+	test.CheckEqN(t, len(server.Bastions), 2)
+	test.CheckEqS(t, server.Bastions[0].GetPrint(), "deploy@gw:2200")
+	test.CheckEqS(t, server.Bastions[1].GetPrint(), "ops@relay:22")
+
+	_, err = ParseSSHConfigInventory(&path, Server{Name: "cache", FromSSHConfig: "cache-*"})
+	test.IsError(t, err)
+
+	_, err = ParseSSHConfigInventory(nil, s)
+	test.IsError(t, err)
+}
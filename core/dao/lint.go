@@ -0,0 +1,275 @@
+package dao
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	LintWarning = "warning"
+	LintInfo    = "info"
+)
+
+// LintIssue is a semantic problem in a valid config, such as a task that targets no servers.
+type LintIssue struct {
+	Rule    string `json:"rule"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+// Lint checks the config for semantic problems. Servers are not expanded from inventories,
+// so each inventory server counts as a single server.
+func (c *Config) Lint() []LintIssue {
+	issues := []LintIssue{}
+
+	issues = append(issues, c.lintTasks()...)
+	issues = append(issues, c.lintUnused()...)
+	issues = append(issues, c.lintTags()...)
+	issues = append(issues, c.lintBastions()...)
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+
+	return issues
+}
+
+func newLintIssue(re Resource, rule string, level string, format string, a ...any) LintIssue {
+	return LintIssue{
+		Rule:    rule,
+		Level:   level,
+		Message: fmt.Sprintf(format, a...),
+		File:    re.GetContext(),
+		Line:    re.GetContextLine(),
+	}
+}
+
+func (c *Config) lintTasks() []LintIssue {
+	issues := []LintIssue{}
+
+	for i := range c.Tasks {
+		task := &c.Tasks[i]
+		target := task.Target
+
+		// Targets without selectors are meant to be used with runtime flags
+		hasSelector := target.HasSelector()
+
+		servers, err := c.FilterTarget(target, target.Invert)
+		if err != nil {
+			issues = append(issues, newLintIssue(task, "target-no-servers", LintWarning,
+				"target of task `%s` matches no servers: %s", task.ID, err))
+		} else if hasSelector && len(servers) == 0 {
+			issues = append(issues, newLintIssue(task, "target-no-servers", LintWarning,
+				"target of task `%s` matches no servers", task.ID))
+		}
+
+		tty := task.TTY
+		for _, cmd := range task.Tasks {
+			tty = tty || cmd.TTY
+		}
+		if tty && len(servers) > 1 {
+			issues = append(issues, newLintIssue(task, "tty-multiple-hosts", LintWarning,
+				"task `%s` uses tty, which replaces the sake process, but targets %d servers", task.ID, len(servers)))
+		}
+
+		for _, ref := range task.TaskRefs {
+			if ref.Local != nil && *ref.Local && ref.WorkDir != "" && !filepath.IsAbs(ref.WorkDir) {
+				issues = append(issues, newLintIssue(task, "local-work-dir", LintWarning,
+					"work_dir `%s` of local sub-task in task `%s` is relative to the config directory, server work_dir is ignored", ref.WorkDir, task.ID))
+			}
+		}
+
+		if len(servers) == 0 {
+			servers = c.Servers
+		}
+		issues = append(issues, c.lintRegisters(task, servers)...)
+	}
+
+	return issues
+}
+
+// lintRegisters checks for registered variables that are shadowed by env variables,
+// which take precedence over them.
+func (c *Config) lintRegisters(task *Task, servers []Server) []LintIssue {
+	issues := []LintIssue{}
+
+	for _, cmd := range task.Tasks {
+		if cmd.Register == "" {
+			continue
+		}
+
+		names := []string{cmd.Register}
+		for _, suffix := range []string{"_stdout", "_stderr", "_rc", "_failed", "_status"} {
+			names = append(names, cmd.Register+suffix)
+		}
+
+		shadowedBy := ""
+		for _, name := range names {
+			if hasEnv(cmd.Envs, name) || hasEnv(c.Envs, name) {
+				shadowedBy = fmt.Sprintf("env `%s` of task `%s`", name, task.ID)
+				break
+			}
+			for _, server := range servers {
+				if hasEnv(server.Envs, name) {
+					shadowedBy = fmt.Sprintf("env `%s` of server `%s`", name, server.Name)
+					break
+				}
+			}
+			if shadowedBy != "" {
+				break
+			}
+		}
+
+		if shadowedBy != "" {
+			issues = append(issues, newLintIssue(task, "register-shadowed", LintWarning,
+				"register `%s` in task `%s` is shadowed by %s", cmd.Register, task.ID, shadowedBy))
+		}
+	}
+
+	return issues
+}
+
+func (c *Config) lintUnused() []LintIssue {
+	issues := []LintIssue{}
+
+	var specs, targets, themes []string
+	for _, task := range c.Tasks {
+		specs = append(specs, task.SpecRef)
+		targets = append(targets, task.TargetRef)
+		themes = append(themes, task.ThemeRef)
+	}
+	for _, spec := range c.Specs {
+		specs = append(specs, spec.Extends)
+	}
+	for _, target := range c.Targets {
+		targets = append(targets, target.Extends)
+		targets = append(targets, target.Targets...)
+		targets = append(targets, target.ExcludeTargets...)
+	}
+	for _, theme := range c.Themes {
+		themes = append(themes, theme.Extends)
+	}
+
+	for i := range c.Specs {
+		spec := &c.Specs[i]
+		if spec.Name != DEFAULT_SPEC.Name && !slices.Contains(specs, spec.Name) {
+			issues = append(issues, newLintIssue(spec, "unused-spec", LintInfo, "spec `%s` is not used by any task", spec.Name))
+		}
+	}
+
+	for i := range c.Targets {
+		target := &c.Targets[i]
+		if target.Name != DEFAULT_TARGET.Name && !slices.Contains(targets, target.Name) {
+			issues = append(issues, newLintIssue(target, "unused-target", LintInfo, "target `%s` is not used by any task", target.Name))
+		}
+	}
+
+	for i := range c.Themes {
+		theme := &c.Themes[i]
+		if theme.Name != DEFAULT_THEME.Name && !slices.Contains(themes, theme.Name) {
+			issues = append(issues, newLintIssue(theme, "unused-theme", LintInfo, "theme `%s` is not used by any task", theme.Name))
+		}
+	}
+
+	return issues
+}
+
+// lintTags checks for tags in targets that no server has.
+func (c *Config) lintTags() []LintIssue {
+	issues := []LintIssue{}
+	tags := c.GetTags()
+
+	for i := range c.Targets {
+		target := &c.Targets[i]
+		for _, tag := range tagExprTags(target.tagExprs()) {
+			if !slices.Contains(tags, tag) {
+				issues = append(issues, newLintIssue(target, "unused-tag", LintWarning,
+					"tag `%s` in target `%s` is not used by any server", tag, target.Name))
+			}
+		}
+	}
+
+	for i := range c.Tasks {
+		task := &c.Tasks[i]
+		if task.TargetRef != "" {
+			continue
+		}
+		for _, tag := range tagExprTags(task.Target.tagExprs()) {
+			if !slices.Contains(tags, tag) {
+				issues = append(issues, newLintIssue(task, "unused-tag", LintWarning,
+					"tag `%s` in target of task `%s` is not used by any server", tag, task.ID))
+			}
+		}
+	}
+
+	return issues
+}
+
+// tagExprTags returns the tags used in tag expressions, invalid expressions are reported when
+// the config is parsed.
+func tagExprTags(exprs []string) []string {
+	expr, err := ParseTagExprs(exprs)
+	if err != nil {
+		return []string{}
+	}
+	return expr.Tags()
+}
+
+// lintBastions checks for servers that are reached through themselves, either directly or
+// via the bastions of other servers.
+func (c *Config) lintBastions() []LintIssue {
+	issues := []LintIssue{}
+
+	address := func(host string, port uint16) string {
+		return fmt.Sprintf("%s:%d", host, port)
+	}
+
+	jumps := map[string][]string{}
+	for _, server := range c.Servers {
+		addr := address(server.Host, server.Port)
+		for _, bastion := range server.Bastions {
+			jumps[addr] = append(jumps[addr], address(bastion.Host, bastion.Port))
+		}
+	}
+
+	for i := range c.Servers {
+		server := &c.Servers[i]
+		start := address(server.Host, server.Port)
+
+		visited := map[string]bool{}
+		queue := slices.Clone(jumps[start])
+		for len(queue) > 0 {
+			addr := queue[0]
+			queue = queue[1:]
+			if addr == start {
+				issues = append(issues, newLintIssue(server, "bastion-loop", LintWarning,
+					"server `%s` is reached through itself via its bastions", server.Name))
+				break
+			}
+			if visited[addr] {
+				continue
+			}
+			visited[addr] = true
+			queue = append(queue, jumps[addr]...)
+		}
+	}
+
+	return issues
+}
+
+func hasEnv(envs []string, name string) bool {
+	for _, env := range envs {
+		if strings.HasPrefix(env, name+"=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/alajmo/sake/core/test"
+)
+
+func TestLint(t *testing.T) {
+	local := true
+	config := Config{
+		Servers: []Server{
+			{Name: "a", Host: "a.lan", Port: 22, Tags: []string{"web"}, Bastions: []Bastion{{Host: "b.lan", Port: 22}}},
+			{Name: "b", Host: "b.lan", Port: 22, Envs: []string{"out=x"}, Bastions: []Bastion{{Host: "a.lan", Port: 22}}},
+		},
+		Specs: []Spec{DEFAULT_SPEC, {Name: "unused"}},
+		Targets: []Target{
+			DEFAULT_TARGET,
+			{Name: "db", Tags: []string{"db"}},
+			{Name: "fleet", Targets: []string{"webplus"}, ExcludeTargets: []string{"canary"}},
+			{Name: "webplus", Servers: []string{"a"}},
+			{Name: "canary", Servers: []string{"b"}},
+		},
+		Themes: []Theme{DEFAULT_THEME},
+		Tasks: []Task{
+			{
+				ID:        "reg",
+				SpecRef:   "default",
+				ThemeRef:  "default",
+				Target:    Target{All: true},
+				Tasks:     []TaskCmd{{Register: "out"}},
+				TaskRefs:  []TaskRef{{Cmd: "ls", Local: &local, WorkDir: "scripts"}},
+				TargetRef: "fleet",
+			},
+		},
+	}
+
+	rules := []string{}
+	for _, issue := range config.Lint() {
+		rules = append(rules, issue.Rule)
+	}
+
+	test.CheckEqualStringArr(t, rules, []string{
+		"local-work-dir",
+		"register-shadowed",
+		"unused-spec",
+		"unused-target",
+		"unused-tag",
+		"bastion-loop",
+		"bastion-loop",
+	})
+}
@@ -0,0 +1,173 @@
+package dao
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/sake/core"
+)
+
+// TaskLoop holds the items a task reference is run for. Literal items are expanded when the
+// config is read, var and register items are resolved per server when the task runs.
+//
+//	tasks:
+//	  - cmd: systemctl restart $item
+//	    loop: [api, worker]
+//
+//	  - cmd: systemctl restart $item
+//	    loop:
+//	      var: services
+//
+//	  - cmd: systemctl restart $item
+//	    loop:
+//	      register: units
+type TaskLoop struct {
+	Items    []any  `yaml:"-"`
+	Var      string `yaml:"var"`
+	Register string `yaml:"register"`
+}
+
+// ParseNodeLoop parses `loop` or its alias `with_items`, only one of them may be set.
+func ParseNodeLoop(loopNode yaml.Node, withItemsNode yaml.Node) (*TaskLoop, error) {
+	if !IsNullNode(loopNode) && !IsNullNode(withItemsNode) {
+		return nil, errors.New("can only define one of the following: loop, with_items")
+	}
+
+	node := loopNode
+	if IsNullNode(node) {
+		node = withItemsNode
+	}
+	if IsNullNode(node) {
+		return nil, nil
+	}
+
+	loop := &TaskLoop{}
+	switch node.Kind {
+	case yaml.SequenceNode:
+		if err := node.Decode(&loop.Items); err != nil {
+			return nil, err
+		}
+		if loop.Items == nil {
+			loop.Items = []any{}
+		}
+	case yaml.MappingNode:
+		if err := node.Decode(loop); err != nil {
+			return nil, err
+		}
+		if (loop.Var == "") == (loop.Register == "") {
+			return nil, errors.New("expected one of the following: var, register")
+		}
+	default:
+		return nil, errors.New("expected list of items or mapping with var or register")
+	}
+
+	return loop, nil
+}
+
+// Resolve returns the items of a var or register loop. A var must be a list or a string, in
+// which case each non-empty line is an item. Register items are the non-empty lines of stdout.
+func (l TaskLoop) Resolve(vars map[string]any, register map[string]string) ([]any, error) {
+	if l.Items != nil {
+		return l.Items, nil
+	}
+
+	if l.Var != "" {
+		value, ok := vars[l.Var]
+		if !ok {
+			return nil, &core.TaskLoopNotFound{Kind: "var", Name: l.Var}
+		}
+
+		switch value := value.(type) {
+		case []any:
+			return value, nil
+		case string:
+			return splitLines(value), nil
+		default:
+			return nil, &core.TaskLoopNotList{Name: l.Var}
+		}
+	}
+
+	stdout, ok := register[l.Register+"_stdout"]
+	if !ok {
+		return nil, &core.TaskLoopNotFound{Kind: "register", Name: l.Register}
+	}
+
+	return splitLines(stdout), nil
+}
+
+// LoopEnvs returns the env variables item and item_index for an item.
+func LoopEnvs(item any, index int) []string {
+	return []string{
+		fmt.Sprintf("item=%s", loopItemString(item)),
+		fmt.Sprintf("item_index=%d", index),
+	}
+}
+
+// LoopVars returns the vars item and item_index for an item.
+func LoopVars(item any, index int) map[string]any {
+	return map[string]any{
+		"item":       item,
+		"item_index": index,
+	}
+}
+
+// expandLoop returns a command per item of a literal loop. Commands that loop over var or
+// register items are returned as is, and expanded when run.
+func expandLoop(cmd TaskCmd, loop *TaskLoop) []TaskCmd {
+	if loop == nil {
+		return []TaskCmd{cmd}
+	}
+
+	if loop.Items == nil {
+		cmd.Loop = loop
+		return []TaskCmd{cmd}
+	}
+
+	cmds := []TaskCmd{}
+	for i, item := range loop.Items {
+		c := cmd
+		c.Name = LoopItemName(cmd.Name, item)
+		c.Envs = MergeEnvs(LoopEnvs(item, i), cmd.Envs)
+		c.Vars = MergeVars(LoopVars(item, i), cmd.Vars)
+		cmds = append(cmds, c)
+	}
+
+	return cmds
+}
+
+// LoopItemName returns the name of the command run for a loop item.
+func LoopItemName(name string, item any) string {
+	if name != "" {
+		return fmt.Sprintf("%s (%s)", name, loopItemString(item))
+	}
+
+	return loopItemString(item)
+}
+
+// loopItemString formats lists and maps as JSON, other values as is.
+func loopItemString(item any) string {
+	switch item.(type) {
+	case []any, map[string]any:
+		if b, err := json.Marshal(item); err == nil {
+			return string(b)
+		}
+	}
+
+	return fmt.Sprint(item)
+}
+
+func splitLines(s string) []any {
+	items := []any{}
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			items = append(items, line)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,57 @@
+package dao
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/sake/core/test"
+)
+
+func TestParseNodeLoop(t *testing.T) {
+	var data = `
+tasks:
+  - cmd: echo $item
+    loop: [a, b]
+  - cmd: echo $item
+    with_items:
+      var: services
+  - cmd: echo $item
+    loop:
+      var: services
+      register: out
+  - cmd: echo $item
+    loop: a
+`
+	taskYAML := TaskYAML{}
+	err := yaml.Unmarshal([]byte(data), &taskYAML)
+	test.CheckErr(t, err)
+
+	loop, err := ParseNodeLoop(taskYAML.Tasks[0].Loop, taskYAML.Tasks[0].WithItems)
+	test.CheckErr(t, err)
+	test.CheckEqN(t, len(loop.Items), 2)
+
+	loop, err = ParseNodeLoop(taskYAML.Tasks[1].Loop, taskYAML.Tasks[1].WithItems)
+	test.CheckErr(t, err)
+	test.CheckEqS(t, loop.Var, "services")
+
+	_, err = ParseNodeLoop(taskYAML.Tasks[2].Loop, taskYAML.Tasks[2].WithItems)
+	test.WantErr(t, err)
+
+	_, err = ParseNodeLoop(taskYAML.Tasks[3].Loop, taskYAML.Tasks[3].WithItems)
+	test.WantErr(t, err)
+}
+
+func TestExpandLoop(t *testing.T) {
+	cmd := TaskCmd{Name: "restart", Cmd: "echo $item", Envs: []string{"foo=bar"}}
+
+	cmds := expandLoop(cmd, &TaskLoop{Items: []any{"api", map[string]any{"name": "db"}}})
+	test.CheckEqN(t, len(cmds), 2)
+	test.CheckEqS(t, cmds[0].Name, "restart (api)")
+	test.CheckEqualStringArr(t, cmds[0].Envs, []string{"item=api", "item_index=0", "foo=bar"})
+	test.CheckEqualStringArr(t, cmds[1].Envs, []string{`item={"name":"db"}`, "item_index=1", "foo=bar"})
+
+	cmds = expandLoop(cmd, &TaskLoop{Var: "services"})
+	test.CheckEqN(t, len(cmds), 1)
+	test.CheckEqS(t, cmds[0].Loop.Var, "services")
+}
@@ -0,0 +1,189 @@
+package dao
+
+import (
+	"sort"
+	"strings"
+)
+
+// Namespaced imports prefix the tasks, specs, targets and themes of the imported config, and
+// the configs it imports, with the namespace:
+//
+//	import:
+//	  - path: lib/db.yaml
+//	    as: db
+//
+// The task deploy in lib/db.yaml is then referenced as db.deploy. References within a namespace
+// resolve to the resource in the same namespace first, and then to its parent namespaces.
+
+func namespaced(namespace string, name string) string {
+	if namespace == "" {
+		return name
+	}
+
+	if name == "" {
+		return namespace
+	}
+
+	return namespace + "." + name
+}
+
+// resolveNamespaced returns the name of the resource a reference from within the namespace
+// refers to, the innermost namespace that has the resource wins.
+func resolveNamespaced(namespace string, name string, exists func(name string) bool) string {
+	for namespace != "" {
+		if n := namespaced(namespace, name); exists(n) {
+			return n
+		}
+
+		i := strings.LastIndex(namespace, ".")
+		if i < 0 {
+			break
+		}
+		namespace = namespace[:i]
+	}
+
+	return name
+}
+
+// resourceCount is used to find the resources loaded from a single config.
+type resourceCount struct {
+	tasks, taskErrors     int
+	specs, specErrors     int
+	targets, targetErrors int
+	themes, themeErrors   int
+}
+
+func (cr *ConfigResources) count() resourceCount {
+	return resourceCount{
+		tasks:        len(cr.Tasks),
+		taskErrors:   len(cr.TaskErrors),
+		specs:        len(cr.Specs),
+		specErrors:   len(cr.SpecErrors),
+		targets:      len(cr.Targets),
+		targetErrors: len(cr.TargetErrors),
+		themes:       len(cr.Themes),
+		themeErrors:  len(cr.ThemeErrors),
+	}
+}
+
+// setNamespace prefixes the resources loaded after from with the namespace.
+func (cr *ConfigResources) setNamespace(namespace string, from resourceCount) {
+	if namespace == "" {
+		return
+	}
+
+	setTask := func(task *Task) {
+		if task.Name == task.ID {
+			task.Name = namespaced(namespace, task.Name)
+		}
+		task.ID = namespaced(namespace, task.ID)
+		task.namespace = namespace
+	}
+	for i := from.tasks; i < len(cr.Tasks); i++ {
+		setTask(&cr.Tasks[i])
+	}
+	for i := from.taskErrors; i < len(cr.TaskErrors); i++ {
+		setTask(cr.TaskErrors[i].Resource)
+	}
+
+	for i := from.specs; i < len(cr.Specs); i++ {
+		cr.Specs[i].Name = namespaced(namespace, cr.Specs[i].Name)
+		cr.Specs[i].namespace = namespace
+	}
+	for i := from.specErrors; i < len(cr.SpecErrors); i++ {
+		cr.SpecErrors[i].Resource.Name = namespaced(namespace, cr.SpecErrors[i].Resource.Name)
+	}
+
+	for i := from.targets; i < len(cr.Targets); i++ {
+		cr.Targets[i].Name = namespaced(namespace, cr.Targets[i].Name)
+		cr.Targets[i].namespace = namespace
+	}
+	for i := from.targetErrors; i < len(cr.TargetErrors); i++ {
+		cr.TargetErrors[i].Resource.Name = namespaced(namespace, cr.TargetErrors[i].Resource.Name)
+	}
+
+	for i := from.themes; i < len(cr.Themes); i++ {
+		cr.Themes[i].Name = namespaced(namespace, cr.Themes[i].Name)
+		cr.Themes[i].namespace = namespace
+	}
+	for i := from.themeErrors; i < len(cr.ThemeErrors); i++ {
+		cr.ThemeErrors[i].Resource.Name = namespaced(namespace, cr.ThemeErrors[i].Resource.Name)
+	}
+}
+
+// resolveNamespaces rewrites references of namespaced resources to the resources they refer to.
+func (cr *ConfigResources) resolveNamespaces() {
+	hasTask := func(id string) bool { _, err := cr.GetTask(id); return err == nil }
+	hasSpec := func(name string) bool { _, err := cr.GetSpec(name); return err == nil }
+	hasTarget := func(name string) bool { _, err := cr.GetTarget(name); return err == nil }
+	hasTheme := func(name string) bool { _, err := cr.GetTheme(name); return err == nil }
+
+	resolve := func(namespace string, name *string, exists func(string) bool) {
+		if namespace != "" && *name != "" {
+			*name = resolveNamespaced(namespace, *name, exists)
+		}
+	}
+
+	for i := range cr.Tasks {
+		task := &cr.Tasks[i]
+		resolve(task.namespace, &task.Extends, hasTask)
+		resolve(task.namespace, &task.SpecRef, hasSpec)
+		resolve(task.namespace, &task.TargetRef, hasTarget)
+		for j := range task.Target.Targets {
+			resolve(task.namespace, &task.Target.Targets[j], hasTarget)
+		}
+		for j := range task.Target.ExcludeTargets {
+			resolve(task.namespace, &task.Target.ExcludeTargets[j], hasTarget)
+		}
+		resolve(task.namespace, &task.ThemeRef, hasTheme)
+		for j := range task.TaskRefs {
+			resolve(task.namespace, &task.TaskRefs[j].Task, hasTask)
+		}
+	}
+
+	for i := range cr.Specs {
+		resolve(cr.Specs[i].namespace, &cr.Specs[i].Extends, hasSpec)
+	}
+
+	for i := range cr.Targets {
+		resolve(cr.Targets[i].namespace, &cr.Targets[i].Extends, hasTarget)
+		for j := range cr.Targets[i].Targets {
+			resolve(cr.Targets[i].namespace, &cr.Targets[i].Targets[j], hasTarget)
+		}
+		for j := range cr.Targets[i].ExcludeTargets {
+			resolve(cr.Targets[i].namespace, &cr.Targets[i].ExcludeTargets[j], hasTarget)
+		}
+	}
+
+	for i := range cr.Themes {
+		resolve(cr.Themes[i].namespace, &cr.Themes[i].Extends, hasTheme)
+	}
+}
+
+type TaskGroup struct {
+	Namespace string
+	Tasks     []Task
+}
+
+// GroupTasksByNamespace groups tasks by the namespace they were imported in, in order of
+// appearance. Tasks that are not namespaced are grouped first.
+func GroupTasksByNamespace(tasks []Task) []TaskGroup {
+	groups := []TaskGroup{}
+	index := map[string]int{}
+
+	for _, task := range tasks {
+		i, ok := index[task.namespace]
+		if !ok {
+			i = len(groups)
+			index[task.namespace] = i
+			groups = append(groups, TaskGroup{Namespace: task.namespace})
+		}
+		groups[i].Tasks = append(groups[i].Tasks, task)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Namespace == "" && groups[j].Namespace != ""
+	})
+
+	return groups
+}
@@ -0,0 +1,53 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/alajmo/sake/core/test"
+)
+
+func TestResolveNamespaced(t *testing.T) {
+	names := []string{"db.migrate", "db.backup.run", "deploy"}
+	exists := func(name string) bool {
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	test.CheckEqS(t, resolveNamespaced("db", "migrate", exists), "db.migrate")
+	test.CheckEqS(t, resolveNamespaced("db.backup", "migrate", exists), "db.migrate")
+	test.CheckEqS(t, resolveNamespaced("db.backup", "run", exists), "db.backup.run")
+	test.CheckEqS(t, resolveNamespaced("db", "deploy", exists), "deploy")
+	test.CheckEqS(t, resolveNamespaced("db", "missing", exists), "missing")
+}
+
+func TestSetNamespace(t *testing.T) {
+	cr := ConfigResources{}
+	cr.Tasks = []Task{{ID: "deploy", Name: "deploy"}}
+	from := cr.count()
+
+	cr.Tasks = append(cr.Tasks,
+		Task{ID: "deploy", Name: "deploy", SpecRef: "quiet", TaskRefs: []TaskRef{{Task: "migrate"}, {Task: "deploy"}}},
+		Task{ID: "migrate", Name: "Migrate database"},
+	)
+	cr.Specs = []Spec{{Name: "quiet"}}
+	cr.setNamespace("db", from)
+	cr.resolveNamespaces()
+
+	test.CheckEqS(t, cr.Tasks[0].ID, "deploy")
+	test.CheckEqS(t, cr.Tasks[1].ID, "db.deploy")
+	test.CheckEqS(t, cr.Tasks[1].Name, "db.deploy")
+	test.CheckEqS(t, cr.Tasks[2].Name, "Migrate database")
+	test.CheckEqS(t, cr.Specs[0].Name, "db.quiet")
+	test.CheckEqS(t, cr.Tasks[1].SpecRef, "db.quiet")
+	test.CheckEqS(t, cr.Tasks[1].TaskRefs[0].Task, "db.migrate")
+	test.CheckEqS(t, cr.Tasks[1].TaskRefs[1].Task, "db.deploy")
+
+	groups := GroupTasksByNamespace(cr.Tasks)
+	test.CheckEqN(t, len(groups), 2)
+	test.CheckEqS(t, groups[1].Namespace, "db")
+	test.CheckEqN(t, len(groups[1].Tasks), 2)
+}
@@ -0,0 +1,242 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/sake/core"
+)
+
+// TaskParam is a named input that a task accepts via `sake run <task> name=value`.
+type TaskParam struct {
+	Name     string
+	Desc     string
+	Required bool
+	Default  *string
+	Allowed  []string
+	Regex    string
+	Secret   bool
+
+	regex *regexp.Regexp
+}
+
+// Unmarshaled from YAML
+type TaskParamYAML struct {
+	Desc     string    `yaml:"desc"`
+	Required bool      `yaml:"required"`
+	Default  yaml.Node `yaml:"default"`
+	Allowed  []string  `yaml:"allowed"`
+	Regex    string    `yaml:"regex"`
+	Secret   bool      `yaml:"secret"`
+}
+
+// ParamPrompt is called for every required param that has no value and no default.
+type ParamPrompt func(param TaskParam) (string, error)
+
+// ParseNodeParams parses the params mapping of a task:
+//
+//	params:
+//	  version:
+//	    required: true
+//	    regex: ^\d+\.\d+\.\d+$
+//	  env:
+//	    default: staging
+//	    allowed: [staging, production]
+func ParseNodeParams(taskName string, node yaml.Node) ([]TaskParam, []error) {
+	var params []TaskParam
+	var paramErrors []error
+
+	if err := CheckIsMappingNode(node); err != nil {
+		return params, []error{err}
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		name := node.Content[i].Value
+		if !REGISTER_REGEX.MatchString(name) {
+			paramErrors = append(paramErrors, &core.TaskParamInvalidName{Task: taskName, Name: name})
+			continue
+		}
+
+		paramYAML := &TaskParamYAML{}
+		if !IsNullNode(*node.Content[i+1]) && node.Content[i+1].Tag != "!!null" {
+			err := node.Content[i+1].Decode(paramYAML)
+			if err != nil {
+				var terr *yaml.TypeError
+				if errors.As(err, &terr) {
+					for _, yerr := range terr.Errors {
+						paramErrors = append(paramErrors, errors.New(yerr))
+					}
+				} else {
+					paramErrors = append(paramErrors, err)
+				}
+				continue
+			}
+		}
+
+		param := TaskParam{
+			Name:     name,
+			Desc:     paramYAML.Desc,
+			Required: paramYAML.Required,
+			Allowed:  paramYAML.Allowed,
+			Regex:    paramYAML.Regex,
+			Secret:   paramYAML.Secret,
+		}
+
+		if !IsNullNode(paramYAML.Default) {
+			if err := CheckIsScalarNode(paramYAML.Default); err != nil {
+				paramErrors = append(paramErrors, fmt.Errorf("param `%s`: default %w", name, err))
+				continue
+			}
+			param.Default = core.Ptr(paramYAML.Default.Value)
+		}
+
+		if param.Regex != "" {
+			re, err := regexp.Compile(param.Regex)
+			if err != nil {
+				paramErrors = append(paramErrors, &core.TaskParamInvalidRegex{Task: taskName, Name: name, Err: err.Error()})
+				continue
+			}
+			param.regex = re
+		}
+
+		if param.Default != nil {
+			if err := param.Validate(taskName, *param.Default); err != nil {
+				paramErrors = append(paramErrors, err)
+				continue
+			}
+		}
+
+		params = append(params, param)
+	}
+
+	return params, paramErrors
+}
+
+// Validate checks value against the allowed values and regex of the param.
+func (p TaskParam) Validate(taskName string, value string) error {
+	if len(p.Allowed) > 0 && !slices.Contains(p.Allowed, value) {
+		reason := fmt.Sprintf("must be one of: %s", strings.Join(p.Allowed, ", "))
+		return &core.TaskParamInvalidValue{Task: taskName, Name: p.Name, Value: p.printValue(value), Reason: reason}
+	}
+
+	if p.regex != nil && !p.regex.MatchString(value) {
+		reason := fmt.Sprintf("must match regex `%s`", p.Regex)
+		return &core.TaskParamInvalidValue{Task: taskName, Name: p.Name, Value: p.printValue(value), Reason: reason}
+	}
+
+	return nil
+}
+
+func (p TaskParam) printValue(value string) string {
+	if p.Secret {
+		return "***"
+	}
+	return value
+}
+
+// ResolveParams validates user arguments against the task params and returns them with defaults
+// and prompted values added. Arguments that match neither a param, an env of the task nor one of
+// envs, the config and server envs, are rejected, so that typos don't silently become unused
+// variables. Tasks without params accept any argument. If prompt is nil, missing required params
+// result in an error.
+func (t Task) ResolveParams(userArgs []string, envs []string, prompt ParamPrompt) ([]string, error) {
+	if len(t.Params) == 0 {
+		return userArgs, nil
+	}
+
+	known := make(map[string]bool)
+	for _, env := range append(slices.Clone(t.Envs), envs...) {
+		known[strings.SplitN(env, "=", 2)[0]] = true
+	}
+	for _, cmd := range t.Tasks {
+		for _, env := range cmd.Envs {
+			known[strings.SplitN(env, "=", 2)[0]] = true
+		}
+	}
+
+	values := make(map[string]string)
+	var unknown []string
+	for _, arg := range userArgs {
+		kv := strings.SplitN(arg, "=", 2)
+		if t.GetParam(kv[0]) == nil && !known[kv[0]] {
+			unknown = append(unknown, kv[0])
+			continue
+		}
+		values[kv[0]] = kv[1]
+	}
+
+	if len(unknown) > 0 {
+		return []string{}, &core.TaskParamUnknown{Task: t.ID, Names: unknown, Params: t.ParamNames()}
+	}
+
+	args := append([]string{}, userArgs...)
+	for _, param := range t.Params {
+		if value, ok := values[param.Name]; ok {
+			if err := param.Validate(t.ID, value); err != nil {
+				return []string{}, err
+			}
+			continue
+		}
+
+		if param.Default != nil {
+			args = append(args, fmt.Sprintf("%s=%s", param.Name, *param.Default))
+			continue
+		}
+
+		if !param.Required {
+			continue
+		}
+
+		if prompt == nil {
+			return []string{}, &core.TaskParamMissing{Task: t.ID, Name: param.Name}
+		}
+
+		value, err := prompt(param)
+		if err != nil {
+			return []string{}, err
+		}
+		if value == "" {
+			return []string{}, &core.TaskParamMissing{Task: t.ID, Name: param.Name}
+		}
+		if err := param.Validate(t.ID, value); err != nil {
+			return []string{}, err
+		}
+		args = append(args, fmt.Sprintf("%s=%s", param.Name, value))
+	}
+
+	return args, nil
+}
+
+// addParams adds params of a referenced task, params already declared take precedence.
+func (t *Task) addParams(params []TaskParam) {
+	for _, param := range params {
+		if t.GetParam(param.Name) == nil {
+			t.Params = append(t.Params, param)
+			if param.Secret {
+				t.addSecretEnvs([]string{param.Name})
+			}
+		}
+	}
+}
+
+func (t Task) GetParam(name string) *TaskParam {
+	for i := range t.Params {
+		if t.Params[i].Name == name {
+			return &t.Params[i]
+		}
+	}
+	return nil
+}
+
+func (t Task) ParamNames() []string {
+	names := make([]string, 0, len(t.Params))
+	for _, param := range t.Params {
+		names = append(names, param.Name)
+	}
+	return names
+}
@@ -0,0 +1,165 @@
+package dao
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/sake/core/test"
+)
+
+func parseTestParams(t *testing.T, data string) ([]TaskParam, []error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &node); err != nil {
+		t.Fatalf("%q", err)
+	}
+
+	return ParseNodeParams("deploy", *node.Content[0])
+}
+
+func TestParseNodeParams(t *testing.T) {
+	params, errs := parseTestParams(t, `
+version:
+  required: true
+  regex: ^\d+\.\d+\.\d+$
+env:
+  default: staging
+  allowed: [staging, production]
+token:
+  secret: true
+debug:
+`)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	if len(params) != 4 {
+		t.Fatalf("Wanted: 4 params, Found: %d", len(params))
+	}
+
+	if !params[0].Required || params[0].Regex == "" {
+		t.Fatalf("Wanted: required param with regex, Found: %+v", params[0])
+	}
+
+	if params[1].Default == nil || *params[1].Default != "staging" {
+		t.Fatalf("Wanted: default staging, Found: %+v", params[1])
+	}
+
+	if !params[2].Secret {
+		t.Fatalf("Wanted: secret param, Found: %+v", params[2])
+	}
+
+	_, errs = parseTestParams(t, `
+1version:
+  required: true
+env:
+  default: dev
+  allowed: [staging, production]
+tag:
+  regex: "[a-"
+`)
+	if len(errs) != 3 {
+		t.Fatalf("Wanted: 3 errors, Found: %v", errs)
+	}
+}
+
+func TestResolveParams(t *testing.T) {
+	params, _ := parseTestParams(t, `
+version:
+  required: true
+  regex: ^\d+\.\d+\.\d+$
+env:
+  default: staging
+  allowed: [staging, production]
+`)
+	task := Task{ID: "deploy", Params: params, Envs: []string{"FORCE=false"}}
+
+	args, err := task.ResolveParams([]string{"version=1.2.3", "FORCE=true"}, []string{}, nil)
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	wanted := []string{"version=1.2.3", "FORCE=true", "env=staging"}
+	if len(args) != len(wanted) {
+		t.Fatalf("Wanted: %q, Found: %q", wanted, args)
+	}
+	for i := range wanted {
+		if args[i] != wanted[i] {
+			t.Fatalf("Wanted: %q, Found: %q", wanted[i], args[i])
+		}
+	}
+
+	for _, userArgs := range [][]string{
+		{"version=1.2"},
+		{"version=1.2.3", "env=dev"},
+		{"version=1.2.3", "verison=1.2.4"},
+		{"env=production"},
+	} {
+		if _, err := task.ResolveParams(userArgs, []string{}, nil); err == nil {
+			t.Fatalf("Wanted: error for %q", userArgs)
+		}
+	}
+
+	prompt := func(param TaskParam) (string, error) { return "2.0.0", nil }
+	args, err = task.ResolveParams([]string{}, []string{}, prompt)
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	if len(args) != 2 || args[0] != "version=2.0.0" || args[1] != "env=staging" {
+		t.Fatalf("Wanted: prompted version, Found: %q", args)
+	}
+
+	// Tasks without params accept any argument
+	args, err = Task{ID: "ping"}.ResolveParams([]string{"foo=bar"}, []string{}, nil)
+	if err != nil || len(args) != 1 {
+		t.Fatalf("Wanted: [foo=bar], Found: %q, %v", args, err)
+	}
+
+	// Config and server envs can be overridden
+	args, err = task.ResolveParams([]string{"version=1.2.3", "REGION=us"}, []string{"REGION=eu"}, nil)
+	if err != nil || len(args) != 3 {
+		t.Fatalf("Wanted: REGION override, Found: %q, %v", args, err)
+	}
+}
+
+func TestNestedTaskParams(t *testing.T) {
+	var data = `
+tasks:
+  migrate:
+    params:
+      version:
+        required: true
+    cmd: echo $version
+
+  deploy:
+    params:
+      env:
+        default: staging
+    tasks:
+      - task: migrate
+      - task: restart
+
+  restart:
+    tasks:
+      - task: migrate
+`
+	configYAML := ConfigYAML{}
+	if err := yaml.Unmarshal([]byte(data), &configYAML); err != nil {
+		t.Fatalf("%q", err)
+	}
+	config, err := configYAML.parseConfig()
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+
+	deploy, err := config.GetTask("deploy")
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	test.CheckEqualStringArr(t, deploy.ParamNames(), []string{"env", "version"})
+
+	_, err = deploy.ResolveParams([]string{}, []string{}, nil)
+	test.IsError(t, err)
+
+	restart, _ := config.GetTask("restart")
+	test.CheckEqualStringArr(t, restart.ParamNames(), []string{"version"})
+}
@@ -0,0 +1,299 @@
+package dao
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/sake/core"
+)
+
+// Resources that are defined once in the schema and referenced via $ref.
+var schemaDefs = map[reflect.Type]string{
+	reflect.TypeOf(ServerYAML{}):    "server",
+	reflect.TypeOf(TaskYAML{}):      "task",
+	reflect.TypeOf(TaskRefYAML{}):   "task_ref",
+	reflect.TypeOf(TaskParamYAML{}): "param",
+	reflect.TypeOf(Spec{}):          "spec",
+	reflect.TypeOf(Target{}):        "target",
+	reflect.TypeOf(Theme{}):         "theme",
+	reflect.TypeOf(Secret{}):        "secret",
+	reflect.TypeOf(ImportYAML{}):    "import",
+}
+
+// Fields whose schema cannot be derived from their Go type, such as yaml.Node fields.
+// Keyed by <definition>.<field>.
+var schemaFields = map[string]map[string]any{
+	"config.import":  {"type": "array", "items": schemaOneOf(map[string]any{"type": "string"}, schemaRef("import"))},
+	"config.env":     schemaRef("env"),
+	"config.vars":    {"type": "object"},
+	"config.themes":  schemaMapOf(schemaRef("theme")),
+	"config.specs":   schemaMapOf(schemaRef("spec")),
+	"config.targets": schemaMapOf(schemaRef("target")),
+	"config.servers": schemaMapOf(schemaRef("server")),
+	"config.tasks":   schemaMapOf(schemaOneOf(map[string]any{"type": "string"}, schemaRef("task"))),
+	"config.secrets": schemaMapOf(schemaRef("secret")),
+
+	"config.server_groups": schemaMapOf(schemaRef("server_group")),
+
+	"server.hosts": schemaOneOf(
+		map[string]any{"type": "string"},
+		map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+	),
+	"server.env":  schemaRef("env"),
+	"server.vars": {"type": "object"},
+
+	"task.env":    schemaRef("env"),
+	"task.params": schemaMapOf(schemaRef("param")),
+	"task.spec":   schemaOneOf(map[string]any{"type": "string"}, schemaRef("spec")),
+	"task.target": schemaOneOf(map[string]any{"type": "string"}, schemaRef("target")),
+	"task.theme":  schemaOneOf(map[string]any{"type": "string"}, schemaRef("theme")),
+
+	"task_ref.env":        schemaRef("env"),
+	"task_ref.loop":       schemaRef("loop"),
+	"task_ref.with_items": schemaRef("loop"),
+
+	"param.default": {"type": []string{"string", "number", "boolean"}},
+}
+
+// Schema returns a JSON Schema of the config, used by editors to validate and complete sake.yaml files.
+func Schema() map[string]any {
+	defs := map[string]any{
+		"env": schemaMapOf(schemaOneOf(
+			map[string]any{"type": []string{"string", "number", "boolean"}},
+			map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"value":  map[string]any{"type": "string"},
+					"secret": map[string]any{"type": "boolean"},
+				},
+				"additionalProperties": false,
+			},
+		)),
+		"loop": schemaOneOf(
+			map[string]any{"type": "array"},
+			map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"var":      map[string]any{"type": "string"},
+					"register": map[string]any{"type": "string"},
+				},
+				"additionalProperties": false,
+			},
+		),
+	}
+
+	for t, name := range schemaDefs {
+		defs[name] = structSchema(t, name)
+	}
+
+	// Server groups accept the same fields as servers, except the fields that define hosts
+	group := structSchema(reflect.TypeOf(ServerYAML{}), "server")
+	for _, key := range serverHostKeys {
+		delete(group["properties"].(map[string]any), key)
+	}
+	defs["server_group"] = group
+
+	schema := structSchema(reflect.TypeOf(ConfigYAML{}), "config")
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "sake config"
+	schema["$defs"] = defs
+
+	return schema
+}
+
+func structSchema(t reflect.Type, def string) map[string]any {
+	properties := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || name == "" || name == "-" || name == "_" {
+			continue
+		}
+
+		if s, ok := schemaFields[def+"."+name]; ok {
+			properties[name] = s
+		} else {
+			properties[name] = typeSchema(field.Type, def+"."+name)
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func typeSchema(t reflect.Type, def string) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if name, ok := schemaDefs[t]; ok {
+		return schemaRef(name)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), def)}
+	case reflect.Map:
+		return map[string]any{"type": "object"}
+	case reflect.Struct:
+		if t == reflect.TypeOf(yaml.Node{}) {
+			return map[string]any{}
+		}
+		return structSchema(t, def)
+	default:
+		return map[string]any{}
+	}
+}
+
+func schemaRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/$defs/" + name}
+}
+
+func schemaMapOf(value map[string]any) map[string]any {
+	return map[string]any{"type": "object", "additionalProperties": value}
+}
+
+func schemaOneOf(schemas ...map[string]any) map[string]any {
+	return map[string]any{"oneOf": schemas}
+}
+
+// CheckUnknownFields reads the config and the configs it imports, and returns an error listing
+// fields that are not part of the config schema, such as misspelled fields.
+func CheckUnknownFields(configPath string) error {
+	schema := Schema()
+	msg := ""
+
+	cr := ConfigResources{LockPath: filepath.Join(filepath.Dir(configPath), IMPORT_LOCK_FILE)}
+	visited := map[string]bool{}
+	paths := []string{configPath}
+	for len(paths) > 0 {
+		path := paths[0]
+		paths = paths[1:]
+		if visited[path] {
+			continue
+		}
+		visited[path] = true
+
+		dat, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var node yaml.Node
+		if err := yaml.Unmarshal(dat, &node); err != nil || len(node.Content) == 0 {
+			continue
+		}
+		root := node.Content[0]
+
+		errs := []error{}
+		checkUnknownFields(root, schema, schema["$defs"].(map[string]any), "", &errs)
+		if len(errs) > 0 {
+			re := &ConfigYAML{Path: path}
+			msg += FormatErrors(re, errs).Error()
+		}
+
+		// Imports, errors in resolving them are reported when reading the config
+		if imports := mappingValue(root, "import"); imports != nil && imports.Kind == yaml.SequenceNode {
+			for _, imp := range imports.Content {
+				if imp.Kind == yaml.MappingNode {
+					imp = mappingValue(imp, "path")
+				}
+				if imp == nil || imp.Kind != yaml.ScalarNode {
+					continue
+				}
+				p, err := cr.resolveImport(filepath.Dir(path), imp.Value)
+				if err == nil {
+					paths = append(paths, p)
+				}
+			}
+		}
+	}
+
+	if msg != "" {
+		return &core.ConfigErr{Msg: msg}
+	}
+
+	return nil
+}
+
+func checkUnknownFields(node *yaml.Node, schema map[string]any, defs map[string]any, path string, errs *[]error) {
+	if ref, ok := schema["$ref"].(string); ok {
+		schema = defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+	}
+
+	if oneOf, ok := schema["oneOf"].([]map[string]any); ok {
+		schema = nil
+		for _, s := range oneOf {
+			if ref, ok := s["$ref"].(string); ok {
+				s = defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+			}
+			if schemaMatchesKind(s, node.Kind) {
+				schema = s
+				break
+			}
+		}
+		if schema == nil {
+			return
+		}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		properties, _ := schema["properties"].(map[string]any)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			value := node.Content[i+1]
+			if key.Value == "<<" {
+				continue
+			}
+
+			if s, ok := properties[key.Value]; ok {
+				checkUnknownFields(value, s.(map[string]any), defs, joinSchemaPath(path, key.Value), errs)
+			} else if s, ok := schema["additionalProperties"].(map[string]any); ok {
+				checkUnknownFields(value, s, defs, joinSchemaPath(path, key.Value), errs)
+			} else if schema["additionalProperties"] == false {
+				*errs = append(*errs, &core.UnknownField{Line: key.Line, Field: key.Value, Path: path})
+			}
+		}
+	case yaml.SequenceNode:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range node.Content {
+				checkUnknownFields(item, items, defs, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	}
+}
+
+func schemaMatchesKind(schema map[string]any, kind yaml.Kind) bool {
+	switch schema["type"] {
+	case "object":
+		return kind == yaml.MappingNode
+	case "array":
+		return kind == yaml.SequenceNode
+	default:
+		return kind == yaml.ScalarNode
+	}
+}
+
+func joinSchemaPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,57 @@
+package dao
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alajmo/sake/core/test"
+)
+
+func TestCheckUnknownFields(t *testing.T) {
+	dir := t.TempDir()
+	config := `
+import: [lib.yaml]
+server_groups:
+  lan:
+    usr: deploy
+    shell: bash
+servers:
+  localhost:
+    extends: lan
+    host: localhost
+    env:
+      foo: bar
+tasks:
+  ping: echo pong
+  deploy:
+    spec:
+      ignore_error: true
+    tasks:
+      - task: ping
+        loop: [a, b]
+`
+	lib := `
+tasks:
+  lib:
+    cmdd: echo 123
+`
+	err := os.WriteFile(filepath.Join(dir, "sake.yaml"), []byte(config), 0644)
+	test.CheckErr(t, err)
+	err = os.WriteFile(filepath.Join(dir, "lib.yaml"), []byte(lib), 0644)
+	test.CheckErr(t, err)
+
+	err = CheckUnknownFields(filepath.Join(dir, "sake.yaml"))
+	test.WantErr(t, err)
+
+	msg := err.Error()
+	for _, wanted := range []string{"`ignore_error` in `tasks.deploy.spec`", "sake.yaml:17", "`usr` in `server_groups.lan`", "sake.yaml:5", "`cmdd` in `tasks.lib`", "lib.yaml:4"} {
+		if !strings.Contains(msg, wanted) {
+			t.Fatalf("Wanted %q in %q", wanted, msg)
+		}
+	}
+	if strings.Contains(msg, "loop") || strings.Contains(msg, "foo") || strings.Contains(msg, "shell") {
+		t.Fatalf("Found unexpected error in %q", msg)
+	}
+}
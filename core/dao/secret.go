@@ -0,0 +1,381 @@
+package dao
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/sake/core"
+)
+
+const (
+	SECRET_PREFIX = "secret://"
+
+	SecretVault   = "vault"
+	SecretEnvFile = "env_file"
+	SecretCommand = "command"
+)
+
+// Secret is a provider that secret references `secret://<provider>/<key>` are resolved against.
+type Secret struct {
+	Name     string `yaml:"-"`
+	Type     string `yaml:"type"`
+	Path     string `yaml:"path"`
+	Cmd      string `yaml:"cmd"`
+	Password string `yaml:"password"`
+
+	context     string // config path
+	contextLine int    // defined at
+}
+
+func (s *Secret) GetContext() string {
+	return s.context
+}
+
+func (s *Secret) GetContextLine() int {
+	return s.contextLine
+}
+
+// Secret providers and the values resolved from them, populated once the config is read.
+var secretStore = struct {
+	sync.Mutex
+	providers map[string]Secret
+	values    map[string]map[string]string
+	loading   map[string]chan struct{} // providers being loaded, closed once loaded
+	commands  map[string]chan struct{} // references being resolved by command providers, closed once resolved
+}{}
+
+// RegisterSecretEnvs registers the values of the env variables named in secrets, so they're masked in output.
+func RegisterSecretEnvs(envs []string, secrets []string) {
+	for _, env := range envs {
+		kv := strings.SplitN(env, "=", 2)
+		if len(kv) == 2 && slices.Contains(secrets, kv[0]) {
+			core.AddSecret(kv[1])
+		}
+	}
+}
+
+// ParseSecretsYAML parses the secrets dictionary and returns it as a list.
+//
+//	secrets:
+//	  vault:
+//	    type: vault
+//	    path: secrets.vault
+//	  dotenv:
+//	    type: env_file
+//	    path: .env.secret
+//	  pass:
+//	    type: command
+//	    cmd: pass show $SAKE_SECRET_KEY
+func (c *ConfigYAML) ParseSecretsYAML() ([]Secret, []ResourceErrors[Secret]) {
+	var secrets []Secret
+	count := len(c.Secrets.Content)
+
+	secretErrors := []ResourceErrors[Secret]{}
+	j := -1
+	for i := 0; i < count; i += 2 {
+		j += 1
+		secret := &Secret{
+			Name:        c.Secrets.Content[i].Value,
+			context:     c.Path,
+			contextLine: c.Secrets.Content[i].Line,
+		}
+		re := ResourceErrors[Secret]{Resource: secret, Errors: []error{}}
+		secretErrors = append(secretErrors, re)
+
+		err := c.Secrets.Content[i+1].Decode(secret)
+		if err != nil {
+			secretErrors[j].Errors = append(secretErrors[j].Errors, err)
+			continue
+		}
+
+		switch secret.Type {
+		case SecretVault, SecretEnvFile:
+			if secret.Path == "" {
+				secretErrors[j].Errors = append(secretErrors[j].Errors, &core.SecretMissingField{Name: secret.Name, Field: "path"})
+				continue
+			}
+			secret.Path = os.ExpandEnv(secret.Path)
+			if !filepath.IsAbs(secret.Path) {
+				secret.Path = filepath.Join(c.Dir, secret.Path)
+			}
+		case SecretCommand:
+			if secret.Cmd == "" {
+				secretErrors[j].Errors = append(secretErrors[j].Errors, &core.SecretMissingField{Name: secret.Name, Field: "cmd"})
+				continue
+			}
+		default:
+			secretErrors[j].Errors = append(secretErrors[j].Errors, &core.SecretInvalidType{Name: secret.Name, Type: secret.Type})
+			continue
+		}
+
+		secrets = append(secrets, *secret)
+	}
+
+	return secrets, secretErrors
+}
+
+// SetSecrets sets the providers that secret references are resolved against.
+func SetSecrets(secrets []Secret) {
+	secretStore.Lock()
+	defer secretStore.Unlock()
+
+	secretStore.providers = make(map[string]Secret)
+	secretStore.values = make(map[string]map[string]string)
+	secretStore.loading = make(map[string]chan struct{})
+	secretStore.commands = make(map[string]chan struct{})
+	for _, s := range secrets {
+		secretStore.providers[s.Name] = s
+	}
+}
+
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SECRET_PREFIX)
+}
+
+// ResolveSecret resolves a reference of the form `secret://<provider>/<key>`. Resolved values
+// are registered, so they're masked in output.
+func ResolveSecret(ref string) (string, error) {
+	return resolveSecret(ref, []string{})
+}
+
+// resolveSecret resolves ref, visited holds the vaults whose password led to ref, to detect
+// circular vault passwords.
+func resolveSecret(ref string, visited []string) (string, error) {
+	name, key, found := strings.Cut(strings.TrimPrefix(ref, SECRET_PREFIX), "/")
+	if !found || name == "" || key == "" {
+		return "", &core.SecretRefInvalid{Ref: ref}
+	}
+
+	secretStore.Lock()
+	provider, ok := secretStore.providers[name]
+	if !ok {
+		secretStore.Unlock()
+		return "", &core.SecretProviderNotFound{Name: name}
+	}
+
+	values, ok := secretStore.values[name]
+	if !ok {
+		if slices.Contains(visited, name) || circularPassword(name, visited) {
+			secretStore.Unlock()
+			return "", &core.SecretResolveFailed{Ref: ref, Err: "circular secret reference in vault password"}
+		}
+
+		// References to a provider that's being loaded wait for it to load
+		if done, running := secretStore.loading[name]; running {
+			secretStore.Unlock()
+			<-done
+			return resolveSecret(ref, visited)
+		}
+		done := make(chan struct{})
+		secretStore.loading[name] = done
+		secretStore.Unlock()
+
+		// Loaded without holding the lock, since the vault password may reference another secret
+		var err error
+		switch provider.Type {
+		case SecretVault:
+			values, err = loadVaultSecrets(provider, visited)
+		case SecretEnvFile:
+			values, err = loadEnvFileSecrets(provider)
+		default:
+			values = make(map[string]string)
+		}
+
+		secretStore.Lock()
+		delete(secretStore.loading, name)
+		close(done)
+		if err != nil {
+			secretStore.Unlock()
+			return "", &core.SecretResolveFailed{Ref: ref, Err: err.Error()}
+		}
+		secretStore.values[name] = values
+	}
+
+	value, ok := values[key]
+	if !ok && provider.Type == SecretCommand {
+		// Run without holding the lock, so a slow command doesn't block resolving other secrets,
+		// references to a key that's being resolved wait for the running command instead
+		if done, running := secretStore.commands[ref]; running {
+			secretStore.Unlock()
+			<-done
+			return resolveSecret(ref, visited)
+		}
+		done := make(chan struct{})
+		secretStore.commands[ref] = done
+		secretStore.Unlock()
+
+		value, err := runSecretCommand(provider, key)
+
+		secretStore.Lock()
+		delete(secretStore.commands, ref)
+		close(done)
+		if err != nil {
+			secretStore.Unlock()
+			return "", &core.SecretResolveFailed{Ref: ref, Err: err.Error()}
+		}
+		values[key] = value
+		secretStore.Unlock()
+
+		core.AddSecret(value)
+
+		return value, nil
+	}
+	secretStore.Unlock()
+
+	if !ok {
+		return "", &core.SecretNotFound{Provider: name, Key: key}
+	}
+
+	core.AddSecret(value)
+
+	return value, nil
+}
+
+// circularPassword reports whether the password of the vault leads back to one of the visited
+// vaults, in which case waiting for another goroutine to load it would never finish.
+// Must be called with the lock held.
+func circularPassword(name string, visited []string) bool {
+	if _, ok := os.LookupEnv("SAKE_VAULT_PASSWORD"); ok {
+		return false
+	}
+
+	for range len(secretStore.providers) {
+		provider, ok := secretStore.providers[name]
+		if !ok || provider.Type != SecretVault || !IsSecretRef(provider.Password) {
+			return false
+		}
+		name, _, _ = strings.Cut(strings.TrimPrefix(provider.Password, SECRET_PREFIX), "/")
+		if slices.Contains(visited, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResolveSecretEnvs resolves the secret references in a list of environment variables.
+func ResolveSecretEnvs(envList []string) ([]string, error) {
+	envs := make([]string, 0, len(envList))
+	for _, env := range envList {
+		kv := strings.SplitN(env, "=", 2)
+		if len(kv) == 2 && IsSecretRef(strings.TrimSpace(kv[1])) {
+			value, err := ResolveSecret(strings.TrimSpace(kv[1]))
+			if err != nil {
+				return envs, &core.ConfigEnvFailed{Name: kv[0], Err: err.Error()}
+			}
+			env = fmt.Sprintf("%v=%v", kv[0], value)
+		}
+		envs = append(envs, env)
+	}
+
+	return envs, nil
+}
+
+// loadVaultSecrets decrypts the vault file, which holds a YAML dictionary of secrets.
+// The passphrase is read from SAKE_VAULT_PASSWORD, the provider password, or prompted for.
+func loadVaultSecrets(s Secret, visited []string) (map[string]string, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase, err := vaultPassphrase(s, visited)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := core.DecryptVault(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	if err := yaml.Unmarshal(plaintext, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func vaultPassphrase(s Secret, visited []string) (string, error) {
+	if passphrase, ok := os.LookupEnv("SAKE_VAULT_PASSWORD"); ok {
+		return passphrase, nil
+	}
+
+	if s.Password != "" {
+		if IsSecretRef(s.Password) {
+			return resolveSecret(s.Password, append(slices.Clone(visited), s.Name))
+		}
+		return EvaluatePassword(s.Password)
+	}
+
+	return PromptVaultPassphrase(s.Name)
+}
+
+// PromptVaultPassphrase reads a vault passphrase from the terminal without echo.
+func PromptVaultPassphrase(name string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("no passphrase for vault `%s`, set SAKE_VAULT_PASSWORD", name)
+	}
+
+	fmt.Fprintf(os.Stderr, "Passphrase for vault `%s`: ", name)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+
+	return string(passphrase), err
+}
+
+// loadEnvFileSecrets reads KEY=VALUE lines, ignoring empty lines, comments and `export` prefixes.
+func loadEnvFileSecrets(s Secret) (map[string]string, error) {
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+
+	return values, scanner.Err()
+}
+
+// runSecretCommand runs the provider command with the key in SAKE_SECRET_KEY and returns its output.
+func runSecretCommand(s Secret, key string) (string, error) {
+	cmd := exec.Command("bash", "-c", s.Cmd)
+	cmd.Dir = filepath.Dir(s.context)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("SAKE_SECRET_KEY=%s", key))
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
@@ -0,0 +1,198 @@
+package dao
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alajmo/sake/core"
+)
+
+func TestResolveSecret(t *testing.T) {
+	dir := t.TempDir()
+
+	envFile := filepath.Join(dir, ".env")
+	err := os.WriteFile(envFile, []byte("# comment\nexport TOKEN=\"abc123\"\nUSER=deploy\n"), 0600)
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+
+	vault, err := core.EncryptVault([]byte("db_password: hunter2\n"), "passphrase")
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	vaultFile := filepath.Join(dir, "secrets.vault")
+	if err := os.WriteFile(vaultFile, vault, 0600); err != nil {
+		t.Fatalf("%q", err)
+	}
+	t.Setenv("SAKE_VAULT_PASSWORD", "passphrase")
+
+	SetSecrets([]Secret{
+		{Name: "dotenv", Type: SecretEnvFile, Path: envFile},
+		{Name: "vault", Type: SecretVault, Path: vaultFile},
+		{Name: "cmd", Type: SecretCommand, Cmd: "echo value-$SAKE_SECRET_KEY", context: envFile},
+	})
+
+	envs, err := EvaluateEnv([]string{
+		"TOKEN=secret://dotenv/TOKEN",
+		"DB_PASSWORD=secret://vault/db_password",
+		"API_KEY=secret://cmd/api",
+		"PLAIN=text",
+	})
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+
+	wanted := []string{"TOKEN=abc123", "DB_PASSWORD=hunter2", "API_KEY=value-api", "PLAIN=text"}
+	for i := range wanted {
+		if envs[i] != wanted[i] {
+			t.Fatalf(`Wanted: %q, Found: %q`, wanted[i], envs[i])
+		}
+	}
+
+	if masked := core.MaskSecrets("password is hunter2"); masked != "password is ***" {
+		t.Fatalf(`Wanted: %q, Found: %q`, "password is ***", masked)
+	}
+
+	for _, ref := range []string{"secret://dotenv/MISSING", "secret://unknown/key", "secret://dotenv"} {
+		if _, err := ResolveSecret(ref); err == nil {
+			t.Fatalf("Wanted: error for %q", ref)
+		}
+	}
+}
+
+func TestVaultPasswordSecretRef(t *testing.T) {
+	dir := t.TempDir()
+
+	envFile := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envFile, []byte("VAULT_PASSWORD=passphrase\n"), 0600); err != nil {
+		t.Fatalf("%q", err)
+	}
+
+	vault, err := core.EncryptVault([]byte("api_key: s3cr3t-key\n"), "passphrase")
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	vaultFile := filepath.Join(dir, "secrets.vault")
+	if err := os.WriteFile(vaultFile, vault, 0600); err != nil {
+		t.Fatalf("%q", err)
+	}
+
+	SetSecrets([]Secret{
+		{Name: "dotenv", Type: SecretEnvFile, Path: envFile},
+		{Name: "vault", Type: SecretVault, Path: vaultFile, Password: "secret://dotenv/VAULT_PASSWORD"},
+		{Name: "loop", Type: SecretVault, Path: vaultFile, Password: "secret://loop/password"},
+		{Name: "a", Type: SecretVault, Path: vaultFile, Password: "secret://b/password"},
+		{Name: "b", Type: SecretVault, Path: vaultFile, Password: "secret://a/password"},
+	})
+
+	value, err := ResolveSecret("secret://vault/api_key")
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	if value != "s3cr3t-key" {
+		t.Fatalf(`Wanted: %q, Found: %q`, "s3cr3t-key", value)
+	}
+
+	for _, ref := range []string{"secret://loop/api_key", "secret://a/api_key"} {
+		if _, err := ResolveSecret(ref); err == nil {
+			t.Fatalf("Wanted: error for circular vault password %q", ref)
+		}
+	}
+
+	// Vaults whose passwords reference each other fail rather than wait on each other
+	SetSecrets([]Secret{
+		{Name: "a", Type: SecretVault, Path: vaultFile, Password: "secret://b/password"},
+		{Name: "b", Type: SecretVault, Path: vaultFile, Password: "secret://a/password"},
+	})
+	var wg sync.WaitGroup
+	for _, ref := range []string{"secret://a/api_key", "secret://b/api_key"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ResolveSecret(ref); err == nil {
+				t.Errorf("Wanted: error for circular vault password %q", ref)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestVaultConcurrentLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	vault, err := core.EncryptVault([]byte("api_key: s3cr3t-key\nuser: deploy\n"), "passphrase")
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	vaultFile := filepath.Join(dir, "secrets.vault")
+	if err := os.WriteFile(vaultFile, vault, 0600); err != nil {
+		t.Fatalf("%q", err)
+	}
+
+	SetSecrets([]Secret{
+		{Name: "pass", Type: SecretCommand, Cmd: "sleep 0.5; echo passphrase", context: vaultFile},
+		{Name: "vault", Type: SecretVault, Path: vaultFile, Password: "secret://pass/vault"},
+	})
+
+	// References to a vault that's being loaded wait for it instead of failing as circular
+	var wg sync.WaitGroup
+	for _, ref := range []string{"secret://vault/api_key", "secret://vault/user", "secret://vault/api_key"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ResolveSecret(ref); err != nil {
+				t.Errorf("Wanted: %q resolved, Found: %v", ref, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSecretCommandOutsideLock(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envFile, []byte("TOKEN=abc123\n"), 0600); err != nil {
+		t.Fatalf("%q", err)
+	}
+	runs := filepath.Join(dir, "runs")
+
+	SetSecrets([]Secret{
+		{Name: "dotenv", Type: SecretEnvFile, Path: envFile},
+		{Name: "slow", Type: SecretCommand, Cmd: "echo run >> " + runs + "; sleep 1; echo slow-$SAKE_SECRET_KEY", context: envFile},
+	})
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value, err := ResolveSecret("secret://slow/key"); err != nil || value != "slow-key" {
+				t.Errorf("Wanted: %q, Found: %q, %v", "slow-key", value, err)
+			}
+		}()
+	}
+
+	// A running secret command doesn't block resolving other secrets
+	time.Sleep(200 * time.Millisecond)
+	start := time.Now()
+	if _, err := ResolveSecret("secret://dotenv/TOKEN"); err != nil {
+		t.Fatalf("%q", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Wanted: secret resolved while command runs, took %v", elapsed)
+	}
+
+	// References to the same key run the command once
+	wg.Wait()
+	out, err := os.ReadFile(runs)
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	if n := strings.Count(string(out), "run"); n != 1 {
+		t.Fatalf("Wanted: 1 run, Found: %d", n)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -19,24 +20,40 @@ import (
 	"github.com/alajmo/sake/core"
 )
 
+const (
+	ConnectionSSH     = "ssh"
+	ConnectionDocker  = "docker"
+	ConnectionKubectl = "kubectl"
+)
+
 type Server struct {
-	Name         string
-	Desc         string
-	Host         string
-	Inventory    string
-	Bastions     []Bastion
-	User         string
-	Port         uint16
-	Local        bool
-	Tags         []string
-	Envs         []string
-	Shell        string
-	WorkDir      string
-	IdentityFile *string
-	Password     *string
+	Name              string
+	Desc              string
+	Host              string
+	Inventory         string
+	InventoryFormat   string // ansible or empty
+	InventoryCacheTTL time.Duration
+	FromSSHConfig     string         // host patterns in ssh config
+	FromSSHConfigTags *regexp.Regexp // capture groups of host aliases become tags
+	Bastions          []Bastion
+	User              string
+	Port              uint16
+	Local             bool
+	Connection        string // ssh, docker or kubectl
+	Container         string // kubectl only, container in pod
+	Namespace         string // kubectl only
+	Tags              []string
+	Envs              []string
+	SecretEnvs        []string // names of envs marked as secret
+	Vars              map[string]any
+	Shell             string
+	WorkDir           string
+	IdentityFile      *string
+	Password          *string
 
 	// Internal
 	Group   string
+	Extends string // server group
 	PubFile *string
 
 	RootDir     string // config dir
@@ -55,22 +72,31 @@ func (b Bastion) GetPrint() string {
 }
 
 type ServerYAML struct {
-	Name         string    `yaml:"-"`
-	Desc         string    `yaml:"desc"`
-	Host         string    `yaml:"host"`
-	Hosts        yaml.Node `yaml:"hosts"`
-	Inventory    string    `yaml:"inventory"`
-	Bastion      string    `yaml:"bastion"`
-	Bastions     []string  `yaml:"bastions"`
-	User         string    `yaml:"user"`
-	Port         uint16    `yaml:"port"`
-	Local        bool      `yaml:"local"`
-	Tags         []string  `yaml:"tags"`
-	Env          yaml.Node `yaml:"env"`
-	Shell        string    `yaml:"shell"`
-	WorkDir      string    `yaml:"work_dir"`
-	IdentityFile *string   `yaml:"identity_file"`
-	Password     *string   `yaml:"password"`
+	Name              string    `yaml:"-"`
+	Desc              string    `yaml:"desc"`
+	Host              string    `yaml:"host"`
+	Hosts             yaml.Node `yaml:"hosts"`
+	Inventory         string    `yaml:"inventory"`
+	InventoryFormat   string    `yaml:"inventory_format"`
+	InventoryCacheTTL string    `yaml:"inventory_cache_ttl"`
+	Extends           string    `yaml:"extends"`
+	FromSSHConfig     string    `yaml:"from_ssh_config"`
+	FromSSHConfigTags string    `yaml:"from_ssh_config_tags"`
+	Bastion           string    `yaml:"bastion"`
+	Bastions          []string  `yaml:"bastions"`
+	User              string    `yaml:"user"`
+	Port              uint16    `yaml:"port"`
+	Local             bool      `yaml:"local"`
+	Connection        string    `yaml:"connection"`
+	Container         string    `yaml:"container"`
+	Namespace         string    `yaml:"namespace"`
+	Tags              []string  `yaml:"tags"`
+	Env               yaml.Node `yaml:"env"`
+	Vars              yaml.Node `yaml:"vars"`
+	Shell             string    `yaml:"shell"`
+	WorkDir           string    `yaml:"work_dir"`
+	IdentityFile      *string   `yaml:"identity_file"`
+	Password          *string   `yaml:"password"`
 }
 
 func (s Server) GetValue(key string, _ int) string {
@@ -90,6 +116,12 @@ func (s Server) GetValue(key string, _ int) string {
 		return strconv.Itoa(int(s.Port))
 	case "local":
 		return strconv.FormatBool(s.Local)
+	case "connection":
+		return s.Connection
+	case "container":
+		return s.Container
+	case "namespace":
+		return s.Namespace
 	case "shell":
 		return s.Shell
 	case "work_dir":
@@ -102,6 +134,8 @@ func (s Server) GetValue(key string, _ int) string {
 		}
 	case "tags":
 		return strings.Join(s.Tags, ",")
+	case "extends":
+		return s.Extends
 	}
 
 	return ""
@@ -126,8 +160,9 @@ func (s *Server) GetNonDefaultEnvs() []string {
 	return envs
 }
 
-// ParseServersYAML parses the servers dictionary and returns it as a list.
-func (c *ConfigYAML) ParseServersYAML() ([]Server, []ResourceErrors[Server]) {
+// ParseServersYAML parses the servers dictionary and returns it as a list. Servers that extend a
+// server group are merged with the server groups of cr.
+func (c *ConfigYAML) ParseServersYAML(cr *ConfigResources) ([]Server, []ResourceErrors[Server]) {
 	var servers []Server
 	count := len(c.Servers.Content)
 
@@ -143,7 +178,13 @@ func (c *ConfigYAML) ParseServersYAML() ([]Server, []ResourceErrors[Server]) {
 		re := ResourceErrors[Server]{Resource: server, Errors: []error{}}
 		serverErrors = append(serverErrors, re)
 
-		err := c.Servers.Content[i+1].Decode(serverYAML)
+		node, err := cr.extendServerNode(c.Servers.Content[i+1])
+		if err != nil {
+			serverErrors[j].Errors = append(serverErrors[j].Errors, err)
+			continue
+		}
+
+		err = node.Decode(serverYAML)
 		if err != nil {
 			for _, yerr := range err.(*yaml.TypeError).Errors {
 				serverErrors[j].Errors = append(serverErrors[j].Errors, errors.New(yerr))
@@ -153,7 +194,42 @@ func (c *ConfigYAML) ParseServersYAML() ([]Server, []ResourceErrors[Server]) {
 
 		serverYAML.Name = c.Servers.Content[i].Value
 
-		if serverYAML.User == "" {
+		switch serverYAML.Connection {
+		case "":
+			serverYAML.Connection = ConnectionSSH
+		case ConnectionSSH, ConnectionDocker, ConnectionKubectl:
+		default:
+			serverErrors[j].Errors = append(serverErrors[j].Errors, &core.ServerInvalidConnection{Name: serverYAML.Name, Connection: serverYAML.Connection})
+			continue
+		}
+
+		if serverYAML.InventoryFormat != "" && serverYAML.InventoryFormat != InventoryFormatAnsible {
+			serverErrors[j].Errors = append(serverErrors[j].Errors, &core.ServerInvalidInventoryFormat{Name: serverYAML.Name, Format: serverYAML.InventoryFormat})
+			continue
+		}
+
+		var inventoryCacheTTL time.Duration
+		if serverYAML.InventoryCacheTTL != "" {
+			inventoryCacheTTL, err = time.ParseDuration(serverYAML.InventoryCacheTTL)
+			if err != nil || inventoryCacheTTL < 0 {
+				serverErrors[j].Errors = append(serverErrors[j].Errors, &core.ServerInvalidInventoryCacheTTL{Name: serverYAML.Name, TTL: serverYAML.InventoryCacheTTL})
+				continue
+			}
+		}
+
+		var fromSSHConfigTags *regexp.Regexp
+		if serverYAML.FromSSHConfigTags != "" {
+			fromSSHConfigTags, err = regexp.Compile(serverYAML.FromSSHConfigTags)
+			if err != nil {
+				serverErrors[j].Errors = append(serverErrors[j].Errors, &core.ServerInvalidSSHConfigTags{Name: serverYAML.Name, Err: err.Error()})
+				continue
+			}
+		}
+
+		// Containers and pods run as their default user unless a user is set
+		isContainer := serverYAML.Connection != ConnectionSSH
+
+		if serverYAML.User == "" && !isContainer {
 			user, err := user.Current()
 
 			if err != nil {
@@ -164,26 +240,40 @@ func (c *ConfigYAML) ParseServersYAML() ([]Server, []ResourceErrors[Server]) {
 			serverYAML.User = user.Username
 		}
 
-		if serverYAML.Port == 0 {
+		if serverYAML.Port == 0 && !isContainer {
 			serverYAML.Port = 22
 		}
 
 		var envs []string
+		var secretEnvs []string
 		if !IsNullNode(serverYAML.Env) {
 			err := CheckIsMappingNode(serverYAML.Env)
 			if err != nil {
 				serverErrors[j].Errors = append(serverErrors[j].Errors, err)
 			} else {
-				envs, err = EvaluateEnv(ParseNodeEnv(serverYAML.Env))
+				// Secret references are resolved when running tasks, once all secret providers are loaded
+				envs, secretEnvs, err = ParseNodeEnv(serverYAML.Env)
+				if err == nil {
+					envs, err = evaluateEnv(envs, false)
+				}
 				if err != nil {
-					for _, yerr := range err.(*yaml.TypeError).Errors {
-						serverErrors[j].Errors = append(serverErrors[j].Errors, errors.New(yerr))
-					}
+					serverErrors[j].Errors = append(serverErrors[j].Errors, err)
 					continue
 				}
 			}
 		}
 
+		var vars map[string]any
+		if !IsNullNode(serverYAML.Vars) {
+			err := CheckIsMappingNode(serverYAML.Vars)
+			if err == nil {
+				vars, err = ParseNodeVars(serverYAML.Vars)
+			}
+			if err != nil {
+				serverErrors[j].Errors = append(serverErrors[j].Errors, err)
+			}
+		}
+
 		bastionDef, err := getServerBastionDefinition(serverYAML)
 		bastions := []Bastion{}
 		if err != nil {
@@ -308,15 +398,21 @@ func (c *ConfigYAML) ParseServersYAML() ([]Server, []ResourceErrors[Server]) {
 			hServer := &Server{
 				Name:         c.Servers.Content[i].Value,
 				Group:        c.Servers.Content[i].Value,
+				Extends:      serverYAML.Extends,
 				Desc:         serverYAML.Desc,
 				Host:         host,
 				User:         user,
 				Port:         port,
 				Local:        serverYAML.Local,
+				Connection:   serverYAML.Connection,
+				Container:    serverYAML.Container,
+				Namespace:    serverYAML.Namespace,
 				Tags:         serverYAML.Tags,
 				Shell:        serverYAML.Shell,
 				WorkDir:      serverYAML.WorkDir,
 				Envs:         serverEnvs,
+				SecretEnvs:   secretEnvs,
+				Vars:         vars,
 				Bastions:     bastions,
 				IdentityFile: identityFile,
 				PubFile:      pubKeyFile,
@@ -349,15 +445,21 @@ func (c *ConfigYAML) ParseServersYAML() ([]Server, []ResourceErrors[Server]) {
 				hServer := &Server{
 					Name:         fmt.Sprintf("%s-%d", c.Servers.Content[i].Value, k),
 					Group:        c.Servers.Content[i].Value,
+					Extends:      serverYAML.Extends,
 					Desc:         serverYAML.Desc,
 					Host:         host,
 					User:         user,
 					Port:         port,
 					Local:        serverYAML.Local,
+					Connection:   serverYAML.Connection,
+					Container:    serverYAML.Container,
+					Namespace:    serverYAML.Namespace,
 					Tags:         serverYAML.Tags,
 					Shell:        serverYAML.Shell,
 					WorkDir:      serverYAML.WorkDir,
 					Envs:         serverEnvs,
+					SecretEnvs:   secretEnvs,
+					Vars:         vars,
 					Bastions:     bastions,
 					IdentityFile: identityFile,
 					PubFile:      pubKeyFile,
@@ -396,15 +498,21 @@ func (c *ConfigYAML) ParseServersYAML() ([]Server, []ResourceErrors[Server]) {
 				hServer := &Server{
 					Name:         fmt.Sprintf("%s-%d", c.Servers.Content[i].Value, k),
 					Group:        c.Servers.Content[i].Value,
+					Extends:      serverYAML.Extends,
 					Desc:         serverYAML.Desc,
 					Host:         host,
 					User:         user,
 					Port:         port,
 					Local:        serverYAML.Local,
+					Connection:   serverYAML.Connection,
+					Container:    serverYAML.Container,
+					Namespace:    serverYAML.Namespace,
 					Tags:         serverYAML.Tags,
 					Shell:        serverYAML.Shell,
 					WorkDir:      serverYAML.WorkDir,
 					Envs:         serverEnvs,
+					SecretEnvs:   secretEnvs,
+					Vars:         vars,
 					Bastions:     bastions,
 					IdentityFile: identityFile,
 					PubFile:      pubKeyFile,
@@ -417,25 +525,35 @@ func (c *ConfigYAML) ParseServersYAML() ([]Server, []ResourceErrors[Server]) {
 
 				servers = append(servers, *hServer)
 			}
-		case "inventory":
-			// User provides command to evaluate to a list of hosts
+		case "inventory", "from_ssh_config":
+			// User provides command to evaluate to a list of hosts, or host patterns to match in ssh config
 			serverEnvs := append(defaultEnvs, envs...)
 			hServer := &Server{
-				Name:         c.Servers.Content[i].Value,
-				Group:        c.Servers.Content[i].Value,
-				Desc:         serverYAML.Desc,
-				Inventory:    serverYAML.Inventory,
-				User:         serverYAML.User,
-				Port:         serverYAML.Port,
-				Local:        serverYAML.Local,
-				Tags:         serverYAML.Tags,
-				Shell:        serverYAML.Shell,
-				WorkDir:      serverYAML.WorkDir,
-				Envs:         serverEnvs,
-				Bastions:     bastions,
-				IdentityFile: identityFile,
-				PubFile:      pubKeyFile,
-				Password:     password,
+				Name:              c.Servers.Content[i].Value,
+				Group:             c.Servers.Content[i].Value,
+				Extends:           serverYAML.Extends,
+				Desc:              serverYAML.Desc,
+				Inventory:         serverYAML.Inventory,
+				InventoryFormat:   serverYAML.InventoryFormat,
+				InventoryCacheTTL: inventoryCacheTTL,
+				FromSSHConfig:     serverYAML.FromSSHConfig,
+				FromSSHConfigTags: fromSSHConfigTags,
+				User:              serverYAML.User,
+				Port:              serverYAML.Port,
+				Local:             serverYAML.Local,
+				Connection:        serverYAML.Connection,
+				Container:         serverYAML.Container,
+				Namespace:         serverYAML.Namespace,
+				Tags:              serverYAML.Tags,
+				Shell:             serverYAML.Shell,
+				WorkDir:           serverYAML.WorkDir,
+				Envs:              serverEnvs,
+				SecretEnvs:        secretEnvs,
+				Vars:              vars,
+				Bastions:          bastions,
+				IdentityFile:      identityFile,
+				PubFile:           pubKeyFile,
+				Password:          password,
 
 				RootDir:     filepath.Dir(c.Path),
 				context:     c.Path,
@@ -479,6 +597,10 @@ func getServerHostDefinition(serverYAML *ServerYAML) (string, error) {
 		hostDef = "inventory"
 		numDefined += 1
 	}
+	if serverYAML.FromSSHConfig != "" {
+		hostDef = "from_ssh_config"
+		numDefined += 1
+	}
 	if serverYAML.Hosts.Kind == 2 && len(serverYAML.Hosts.Content) > 0 {
 		// list of servers
 		numDefined += 1
@@ -503,6 +625,7 @@ func (c *Config) FilterServers(
 	serversFlag []string,
 	tagsFlag []string,
 	regexFlag string,
+	whereFlag []string,
 	invertFlag bool,
 ) ([]Server, error) {
 	var finalServers []Server
@@ -537,7 +660,15 @@ func (c *Config) FilterServers(
 		}
 	}
 
-	finalServers = GetIntersectionServers(allServers, servers, tagServers, regexServers)
+	var whereServers []Server
+	if len(whereFlag) > 0 {
+		whereServers, err = c.GetServersByWhere(whereFlag)
+		if err != nil {
+			return []Server{}, err
+		}
+	}
+
+	finalServers = GetIntersectionServers(allServers, servers, tagServers, regexServers, whereServers)
 
 	if invertFlag {
 		finalServers = GetInvertedServers(c.Servers, finalServers)
@@ -785,24 +916,32 @@ func evalTargetRange(hm map[string][]Server, tr TargetRange) ([]Server, error) {
 
 // Matches on server host
 func (c *Config) GetServersByRegex(r string) ([]Server, error) {
+	servers, err := c.matchServersByRegex(r)
+	if err != nil {
+		return []Server{}, err
+	}
+
+	if len(servers) == 0 {
+		return []Server{}, fmt.Errorf("cannot find server any servers matching regex %s", r)
+	}
+
+	return servers, nil
+}
+
+// matchServersByRegex returns the servers whose host matches r, without failing if none match.
+func (c *Config) matchServersByRegex(r string) ([]Server, error) {
 	pattern, err := regexp.Compile(r)
 	if err != nil {
 		return []Server{}, err
 	}
 
-	// Find servers matching the flag
 	var servers []Server
 	for _, server := range c.Servers {
-		match := pattern.MatchString(server.Host)
-		if match {
+		if pattern.MatchString(server.Host) {
 			servers = append(servers, server)
 		}
 	}
 
-	if len(servers) == 0 {
-		return []Server{}, fmt.Errorf("cannot find server any servers matching regex %s", r)
-	}
-
 	return servers, nil
 }
 
@@ -861,38 +1000,20 @@ func (c *Config) GetServerGroupsAndDesc() []string {
 	return options
 }
 
-// Servers must have all tags to match. For instance, if --tags frontend,backend
+// Servers must match all tag expressions, see ParseTagExpr. For instance, if --tags frontend,backend
 // is passed, then a server must have both tags.
-// We only return error if the flags provided do not exist in the sake config.
+// We only return error if the tags provided do not exist in the sake config.
 func (c *Config) GetServersByTags(tags []string) ([]Server, error) {
-	foundTags := make(map[string]bool)
-	for _, tag := range tags {
-		foundTags[tag] = false
-	}
-
-	// Find servers matching the flag
-	var servers []Server
-	for _, server := range c.Servers {
-		// Variable use to check that all tags are matched
-		numMatched := 0
-		for _, tag := range tags {
-			for _, serverTag := range server.Tags {
-				if serverTag == tag {
-					foundTags[tag] = true
-					numMatched = numMatched + 1
-				}
-			}
-		}
-
-		if numMatched == len(tags) {
-			servers = append(servers, server)
-		}
+	expr, err := ParseTagExprs(tags)
+	if err != nil {
+		return []Server{}, err
 	}
 
+	existingTags := c.GetTags()
 	nonExistingTags := []string{}
-	for k, v := range foundTags {
-		if !v {
-			nonExistingTags = append(nonExistingTags, k)
+	for _, tag := range expr.Tags() {
+		if !slices.Contains(existingTags, tag) {
+			nonExistingTags = append(nonExistingTags, tag)
 		}
 	}
 
@@ -900,7 +1021,29 @@ func (c *Config) GetServersByTags(tags []string) ([]Server, error) {
 		return []Server{}, &core.TagNotFound{Tags: nonExistingTags}
 	}
 
-	return servers, nil
+	return c.matchServersByTagExpr(expr), nil
+}
+
+// matchServersByTags returns the servers matching all tag expressions, without failing if
+// none match or a tag is not used by any server.
+func (c *Config) matchServersByTags(tags []string) ([]Server, error) {
+	expr, err := ParseTagExprs(tags)
+	if err != nil {
+		return []Server{}, err
+	}
+
+	return c.matchServersByTagExpr(expr), nil
+}
+
+func (c *Config) matchServersByTagExpr(expr *TagExpr) []Server {
+	var servers []Server
+	for _, server := range c.Servers {
+		if expr.Match(server.Tags) {
+			servers = append(servers, server)
+		}
+	}
+
+	return servers
 }
 
 func GetIntersectionServers(s ...[]Server) []Server {
@@ -952,42 +1095,6 @@ func GetInvertedServers(allServers []Server, excludeServers []Server) []Server {
 	return servers
 }
 
-func CreateInventoryServers(inputHost string, i int, server Server, userArgs []string) (Server, error) {
-	user, host, port, err := core.ParseHostName(inputHost, server.User, server.Port)
-	if err != nil {
-		return server, err
-	}
-
-	serverEnvs := append(server.Envs, []string{
-		fmt.Sprintf("S_HOST=%s", host),
-		fmt.Sprintf("S_USER=%s", user),
-		fmt.Sprintf("S_PORT=%d", port),
-	}...)
-	serverEnvs = append(serverEnvs, userArgs...)
-
-	iServer := &Server{
-		Name:         fmt.Sprintf("%s-%d", server.Name, i),
-		Group:        server.Group,
-		Desc:         server.Desc,
-		Host:         host,
-		User:         user,
-		Port:         port,
-		Local:        server.Local,
-		Tags:         server.Tags,
-		Shell:        server.Shell,
-		WorkDir:      server.WorkDir,
-		Envs:         serverEnvs,
-		IdentityFile: server.IdentityFile,
-		PubFile:      server.PubFile,
-		Password:     server.Password,
-
-		context:     server.context,
-		contextLine: server.contextLine,
-	}
-
-	return *iServer, nil
-}
-
 func SortServers(order string, servers *[]Server) {
 	switch order {
 	case "inventory":
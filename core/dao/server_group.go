@@ -0,0 +1,306 @@
+package dao
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/sake/core"
+)
+
+// Keys that define the hosts of a server, server groups only hold shared settings.
+var serverHostKeys = []string{"host", "hosts", "inventory", "from_ssh_config"}
+
+// Keys where a child server defining one of them does not inherit any of them.
+var serverBastionKeys = []string{"bastion", "bastions"}
+
+// ServerGroup holds settings, such as user, bastion, env and tags, that servers inherit via extends.
+type ServerGroup struct {
+	Name       string
+	Desc       string
+	Extends    string
+	User       string
+	Port       uint16
+	Bastion    []string
+	Tags       []string
+	Envs       []string
+	SecretEnvs []string // names of envs marked as secret
+	Servers    []string // servers that extend the group, directly or via other groups
+
+	context     string     // config path
+	contextLine int        // defined at
+	node        *yaml.Node // definition, used to resolve extends
+}
+
+// serverGroupYAML is used to decode the fields of a server group that are displayed.
+type serverGroupYAML struct {
+	Desc     string    `yaml:"desc"`
+	Extends  string    `yaml:"extends"`
+	User     string    `yaml:"user"`
+	Port     uint16    `yaml:"port"`
+	Bastion  string    `yaml:"bastion"`
+	Bastions []string  `yaml:"bastions"`
+	Tags     []string  `yaml:"tags"`
+	Env      yaml.Node `yaml:"env"`
+}
+
+func (g *ServerGroup) GetContext() string {
+	return g.context
+}
+
+func (g *ServerGroup) GetContextLine() int {
+	return g.contextLine
+}
+
+func (g ServerGroup) GetValue(key string, _ int) string {
+	lkey := strings.ToLower(key)
+	switch lkey {
+	case "name", "server_group", "group":
+		return g.Name
+	case "desc", "description":
+		return g.Desc
+	case "extends":
+		return g.Extends
+	case "user":
+		return g.User
+	case "port":
+		if g.Port == 0 {
+			return ""
+		}
+		return strconv.Itoa(int(g.Port))
+	case "bastion":
+		return strings.Join(g.Bastion, "\n")
+	case "tags":
+		return strings.Join(g.Tags, ",")
+	case "env":
+		return strings.Join(g.Envs, "\n")
+	case "servers":
+		return strings.Join(g.Servers, "\n")
+	}
+
+	return ""
+}
+
+// ParseServerGroupsYAML parses the server groups dictionary and returns it as a list. The
+// settings of a group are merged into servers when the servers are parsed.
+func (c *ConfigYAML) ParseServerGroupsYAML() ([]ServerGroup, []ResourceErrors[ServerGroup]) {
+	var groups []ServerGroup
+	groupErrors := []ResourceErrors[ServerGroup]{}
+
+	for i := 0; i+1 < len(c.ServerGroups.Content); i += 2 {
+		group := &ServerGroup{
+			Name:        c.ServerGroups.Content[i].Value,
+			context:     c.Path,
+			contextLine: c.ServerGroups.Content[i].Line,
+			node:        c.ServerGroups.Content[i+1],
+		}
+		re := ResourceErrors[ServerGroup]{Resource: group, Errors: []error{}}
+
+		if err := CheckIsMappingNode(*group.node); err != nil {
+			re.Errors = append(re.Errors, err)
+		} else {
+			re.Errors = append(re.Errors, group.decode(group.node)...)
+			for _, key := range serverHostKeys {
+				if mappingValue(group.node, key) != nil {
+					re.Errors = append(re.Errors, &core.ServerGroupHostDefined{Name: group.Name, Key: key})
+				}
+			}
+		}
+
+		groups = append(groups, *group)
+		groupErrors = append(groupErrors, re)
+	}
+
+	return groups, groupErrors
+}
+
+// decode sets the displayed fields of the group from its definition.
+func (g *ServerGroup) decode(node *yaml.Node) []error {
+	var groupYAML serverGroupYAML
+	if err := node.Decode(&groupYAML); err != nil {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			errs := []error{}
+			for _, e := range typeErr.Errors {
+				errs = append(errs, errors.New(e))
+			}
+			return errs
+		}
+		return []error{err}
+	}
+
+	g.Desc = groupYAML.Desc
+	g.Extends = groupYAML.Extends
+	g.User = groupYAML.User
+	g.Port = groupYAML.Port
+	g.Tags = groupYAML.Tags
+	g.Bastion = groupYAML.Bastions
+	if groupYAML.Bastion != "" {
+		g.Bastion = []string{groupYAML.Bastion}
+	}
+	g.Envs = []string{}
+	if !IsNullNode(groupYAML.Env) {
+		envs, secrets, err := ParseNodeEnv(groupYAML.Env)
+		if err != nil {
+			return []error{err}
+		}
+		g.Envs = envs
+		g.SecretEnvs = secrets
+	}
+
+	return nil
+}
+
+// resolveServerGroups merges server groups with the groups they extend.
+func (cr *ConfigResources) resolveServerGroups() {
+	for i := range cr.ServerGroups {
+		group := &cr.ServerGroups[i]
+		if group.Extends == "" {
+			continue
+		}
+
+		node, err := resolveExtendsNode("server group", group.Name, cr.lookupServerGroup, mergeServerNodes)
+		if err != nil {
+			cr.appendServerGroupErrors(*group, err)
+			continue
+		}
+
+		if errs := group.decode(node); len(errs) > 0 {
+			cr.appendServerGroupErrors(*group, errs...)
+		}
+	}
+}
+
+func (cr *ConfigResources) appendServerGroupErrors(group ServerGroup, errs ...error) {
+	for i := range cr.ServerGroupErrors {
+		if cr.ServerGroupErrors[i].Resource.Name == group.Name && cr.ServerGroupErrors[i].Resource.context == group.context {
+			cr.ServerGroupErrors[i].Errors = append(cr.ServerGroupErrors[i].Errors, errs...)
+			return
+		}
+	}
+}
+
+func (cr *ConfigResources) lookupServerGroup(name string) (*yaml.Node, string, bool) {
+	for _, group := range cr.ServerGroups {
+		if group.Name == name {
+			parent := ""
+			if extends := mappingValue(group.node, "extends"); extends != nil {
+				parent = extends.Value
+			}
+			return group.node, parent, true
+		}
+	}
+	return nil, "", false
+}
+
+// extendServerNode merges the definition of a server with the server group it extends.
+func (cr *ConfigResources) extendServerNode(node *yaml.Node) (*yaml.Node, error) {
+	extends := mappingValue(node, "extends")
+	if extends == nil || extends.Value == "" {
+		return node, nil
+	}
+
+	if _, _, found := cr.lookupServerGroup(extends.Value); !found {
+		return nil, &core.ServerGroupNotFound{Name: extends.Value}
+	}
+
+	group, err := resolveExtendsNode("server group", extends.Value, cr.lookupServerGroup, mergeServerNodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeServerNodes(group, node), nil
+}
+
+// mergeServerNodes merges servers and server groups like mergeNodes, except that tags are
+// combined, and bastion and bastions are not inherited if the child defines any of them.
+func mergeServerNodes(parent *yaml.Node, child *yaml.Node) *yaml.Node {
+	skip := extendsSkipKeys
+	for _, key := range serverBastionKeys {
+		if mappingValue(child, key) != nil {
+			skip = append(slices.Clone(extendsSkipKeys), serverBastionKeys...)
+			break
+		}
+	}
+
+	merged := mergeMappings(parent, child, skip)
+
+	parentTags, childTags := mappingValue(parent, "tags"), mappingValue(child, "tags")
+	if parentTags == nil || childTags == nil || parentTags.Kind != yaml.SequenceNode || childTags.Kind != yaml.SequenceNode {
+		return merged
+	}
+
+	tags := *childTags
+	tags.Content = slices.Clone(parentTags.Content)
+	for _, tag := range childTags.Content {
+		if !slices.ContainsFunc(tags.Content, func(n *yaml.Node) bool { return n.Value == tag.Value }) {
+			tags.Content = append(tags.Content, tag)
+		}
+	}
+	for i := 0; i+1 < len(merged.Content); i += 2 {
+		if merged.Content[i].Value == "tags" {
+			merged.Content[i+1] = &tags
+		}
+	}
+
+	return merged
+}
+
+// setServerGroupServers sets the servers of each server group.
+func (cr *ConfigResources) setServerGroupServers() {
+	for i := range cr.ServerGroups {
+		cr.ServerGroups[i].Servers = []string{}
+	}
+
+	for _, server := range cr.Servers {
+		group := server.Extends
+		visited := []string{}
+		for group != "" && !slices.Contains(visited, group) {
+			visited = append(visited, group)
+			for i := range cr.ServerGroups {
+				if cr.ServerGroups[i].Name == group {
+					if !slices.Contains(cr.ServerGroups[i].Servers, server.Group) {
+						cr.ServerGroups[i].Servers = append(cr.ServerGroups[i].Servers, server.Group)
+					}
+					group = cr.ServerGroups[i].Extends
+					break
+				}
+			}
+		}
+	}
+}
+
+func (c *Config) GetServerGroupNames() []string {
+	names := []string{}
+	for _, group := range c.ServerGroups {
+		names = append(names, group.Name)
+	}
+
+	return names
+}
+
+func (c *Config) GetServerGroupsByName(names []string) ([]ServerGroup, error) {
+	if len(names) == 0 {
+		return c.ServerGroups, nil
+	}
+
+	var groups []ServerGroup
+	for _, name := range names {
+		found := false
+		for _, group := range c.ServerGroups {
+			if group.Name == name {
+				groups = append(groups, group)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return []ServerGroup{}, &core.ServerGroupNotFound{Name: name}
+		}
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,66 @@
+package dao
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alajmo/sake/core/test"
+)
+
+func TestServerGroups(t *testing.T) {
+	var data = `
+server_groups:
+  base:
+    user: deploy
+    port: 2222
+    tags: [prod]
+    env:
+      REGION: eu
+  web:
+    extends: base
+    bastion: jump
+    tags: [web]
+
+servers:
+  web-1:
+    extends: web
+    host: 10.0.0.1
+    tags: [canary]
+  web-2:
+    extends: web
+    host: 10.0.0.2
+    user: root
+    bastions: [other]
+  db:
+    extends: missing
+    host: 10.0.1.1
+`
+	configYAML := ConfigYAML{}
+	if err := yaml.Unmarshal([]byte(data), &configYAML); err != nil {
+		t.Fatalf("%q", err)
+	}
+
+	cr := ConfigResources{}
+	cr.ServerGroups, cr.ServerGroupErrors = configYAML.ParseServerGroupsYAML()
+	cr.resolveServerGroups()
+	servers, serverErrors := configYAML.ParseServersYAML(&cr)
+	cr.Servers = servers
+	cr.setServerGroupServers()
+
+	web1, web2 := servers[0], servers[1]
+	test.CheckEqS(t, web1.User, "deploy")
+	test.CheckEqN(t, int(web1.Port), 2222)
+	test.CheckEqualStringArr(t, web1.Tags, []string{"prod", "web", "canary"})
+	test.CheckEqualStringArr(t, web1.Envs[len(web1.Envs)-1:], []string{"REGION=eu"})
+	test.CheckEqS(t, web1.Bastions[0].Host, "jump")
+
+	// Bastions of the child replace the bastion of the group
+	test.CheckEqS(t, web2.User, "root")
+	test.CheckEqN(t, len(web2.Bastions), 1)
+	test.CheckEqS(t, web2.Bastions[0].Host, "other")
+
+	test.IsError(t, serverErrors[2].Errors[0])
+	test.CheckEqualStringArr(t, cr.ServerGroups[0].Servers, []string{"web-1", "web-2"})
+	test.CheckEqualStringArr(t, cr.ServerGroups[1].Tags, []string{"prod", "web"})
+}
@@ -20,7 +20,7 @@ func TestFilterServers(t *testing.T) {
 	}
 
 	// Server + Tag
-	ss, err := c.FilterServers(false, []string{"s1", "s2"}, []string{"t1"}, "", false)
+	ss, err := c.FilterServers(false, []string{"s1", "s2"}, []string{"t1"}, "", []string{}, false)
 	test.CheckErr(t, err)
 	wanted := []string{"s1"}
 	test.CheckEqN(t, len(ss), len(wanted))
@@ -29,7 +29,7 @@ func TestFilterServers(t *testing.T) {
 	}
 
 	// Invert
-	ss, err = c.FilterServers(false, []string{"s1", "s2"}, []string{"t1"}, "", true)
+	ss, err = c.FilterServers(false, []string{"s1", "s2"}, []string{"t1"}, "", []string{}, true)
 	test.CheckErr(t, err)
 	wanted = []string{"s2", "s3", "s4", "s5", "s6-1", "s6-2"}
 	test.CheckEqN(t, len(ss), len(wanted))
@@ -38,7 +38,7 @@ func TestFilterServers(t *testing.T) {
 	}
 
 	// Server
-	ss, err = c.FilterServers(false, []string{"s5"}, []string{}, "", false)
+	ss, err = c.FilterServers(false, []string{"s5"}, []string{}, "", []string{}, false)
 	test.CheckErr(t, err)
 	wanted = []string{"s5"}
 	test.CheckEqN(t, len(ss), len(wanted))
@@ -47,7 +47,7 @@ func TestFilterServers(t *testing.T) {
 	}
 
 	// Tag
-	ss, err = c.FilterServers(false, []string{}, []string{"t1"}, "", false)
+	ss, err = c.FilterServers(false, []string{}, []string{"t1"}, "", []string{}, false)
 	test.CheckErr(t, err)
 	wanted = []string{"s1", "s5"}
 	test.CheckEqN(t, len(ss), len(wanted))
@@ -56,7 +56,7 @@ func TestFilterServers(t *testing.T) {
 	}
 
 	// All
-	ss, err = c.FilterServers(true, []string{}, []string{}, "", false)
+	ss, err = c.FilterServers(true, []string{}, []string{}, "", []string{}, false)
 	test.CheckErr(t, err)
 	wanted = []string{"s1", "s2", "s3", "s4", "s5", "s6-1", "s6-2"}
 	test.CheckEqN(t, len(ss), len(wanted))
@@ -65,13 +65,56 @@ func TestFilterServers(t *testing.T) {
 	}
 
 	// Regex
-	ss, err = c.FilterServers(false, []string{}, []string{}, "192.(168|169)", false)
+	ss, err = c.FilterServers(false, []string{}, []string{}, "192.(168|169)", []string{}, false)
 	test.CheckErr(t, err)
 	wanted = []string{"s6-1", "s6-2"}
 	test.CheckEqN(t, len(ss), len(wanted))
 	for i, s := range ss {
 		test.CheckEqS(t, s.Name, wanted[i])
 	}
+
+	// Where + Tag
+	ss, err = c.FilterServers(false, []string{}, []string{"t2"}, "", []string{"group!=s1", "tags=t3"}, false)
+	test.CheckErr(t, err)
+	wanted = []string{"s3"}
+	test.CheckEqN(t, len(ss), len(wanted))
+	for i, s := range ss {
+		test.CheckEqS(t, s.Name, wanted[i])
+	}
+
+	_, err = c.FilterServers(true, []string{}, []string{}, "", []string{"host=10.*"}, false)
+	test.IsError(t, err)
+}
+
+func TestParseWhere(t *testing.T) {
+	s := Server{
+		Name:     "web",
+		User:     "deploy",
+		Port:     2222,
+		Envs:     []string{"REGION=us-1", "REGION=eu-1"},
+		Bastions: []Bastion{{User: "root", Host: "jump1.lan", Port: 22}},
+	}
+
+	for selector, match := range map[string]bool{
+		"user=deploy":      true,
+		"user = root":      false,
+		"port!=22":         true,
+		"env.REGION=eu-*":  true,
+		"env.ZONE!=a":      true,
+		"bastion~=jump1":   true,
+		"bastion~=^jump1$": false,
+	} {
+		w, err := ParseWhere(selector)
+		test.CheckErr(t, err)
+		if w.Match(s) != match {
+			t.Fatalf("Wanted: %s to match %t", selector, match)
+		}
+	}
+
+	for _, invalid := range []string{"user", "=deploy", "hostname=a", "env.=a", "host~=("} {
+		_, err := ParseWhere(invalid)
+		test.IsError(t, err)
+	}
 }
 
 func TestGetServersByTags(t *testing.T) {
@@ -133,7 +176,7 @@ func TestTargetRange(t *testing.T) {
 		Servers: []Server{s1, s2, s3, s4, s5, s6},
 	}
 
-	ss, err := c.FilterServers(false, []string{"s4"}, []string{}, "", false)
+	ss, err := c.FilterServers(false, []string{"s4"}, []string{}, "", []string{}, false)
 	test.CheckErr(t, err)
 	wanted := []string{"s4"}
 	test.CheckEqN(t, len(ss), len(wanted))
@@ -141,7 +184,7 @@ func TestTargetRange(t *testing.T) {
 		test.CheckEqS(t, s.Name, wanted[i])
 	}
 
-	ss, err = c.FilterServers(false, []string{"s5"}, []string{}, "", false)
+	ss, err = c.FilterServers(false, []string{"s5"}, []string{}, "", []string{}, false)
 	test.CheckErr(t, err)
 	wanted = []string{"s5-0", "s5-1"}
 	test.CheckEqN(t, len(ss), len(wanted))
@@ -149,7 +192,7 @@ func TestTargetRange(t *testing.T) {
 		test.CheckEqS(t, s.Name, wanted[i])
 	}
 
-	ss, err = c.FilterServers(false, []string{"s5[:]"}, []string{}, "", false)
+	ss, err = c.FilterServers(false, []string{"s5[:]"}, []string{}, "", []string{}, false)
 	test.CheckErr(t, err)
 	wanted = []string{"s5-0", "s5-1"}
 	test.CheckEqN(t, len(ss), len(wanted))
@@ -157,7 +200,7 @@ func TestTargetRange(t *testing.T) {
 		test.CheckEqS(t, s.Name, wanted[i])
 	}
 
-	ss, err = c.FilterServers(false, []string{"s5[0:]"}, []string{}, "", false)
+	ss, err = c.FilterServers(false, []string{"s5[0:]"}, []string{}, "", []string{}, false)
 	test.CheckErr(t, err)
 	wanted = []string{"s5-0", "s5-1"}
 	test.CheckEqN(t, len(ss), len(wanted))
@@ -165,7 +208,7 @@ func TestTargetRange(t *testing.T) {
 		test.CheckEqS(t, s.Name, wanted[i])
 	}
 
-	ss, err = c.FilterServers(false, []string{"s5[0:10]"}, []string{}, "", false)
+	ss, err = c.FilterServers(false, []string{"s5[0:10]"}, []string{}, "", []string{}, false)
 	test.CheckErr(t, err)
 	wanted = []string{"s5-0", "s5-1"}
 	test.CheckEqN(t, len(ss), len(wanted))
@@ -173,7 +216,7 @@ func TestTargetRange(t *testing.T) {
 		test.CheckEqS(t, s.Name, wanted[i])
 	}
 
-	ss, err = c.FilterServers(false, []string{"s5[:10]"}, []string{}, "", false)
+	ss, err = c.FilterServers(false, []string{"s5[:10]"}, []string{}, "", []string{}, false)
 	test.CheckErr(t, err)
 	wanted = []string{"s5-0", "s5-1"}
 	test.CheckEqN(t, len(ss), len(wanted))
@@ -181,7 +224,7 @@ func TestTargetRange(t *testing.T) {
 		test.CheckEqS(t, s.Name, wanted[i])
 	}
 
-	ss, err = c.FilterServers(false, []string{"s5[:3]"}, []string{}, "", false)
+	ss, err = c.FilterServers(false, []string{"s5[:3]"}, []string{}, "", []string{}, false)
 	test.CheckErr(t, err)
 	wanted = []string{"s5-0", "s5-1"}
 	test.CheckEqN(t, len(ss), len(wanted))
@@ -189,7 +232,7 @@ func TestTargetRange(t *testing.T) {
 		test.CheckEqS(t, s.Name, wanted[i])
 	}
 
-	ss, err = c.FilterServers(false, []string{"s5[0]"}, []string{}, "", false)
+	ss, err = c.FilterServers(false, []string{"s5[0]"}, []string{}, "", []string{}, false)
 	test.CheckErr(t, err)
 	wanted = []string{"s5-0"}
 	test.CheckEqN(t, len(ss), len(wanted))
@@ -197,7 +240,7 @@ func TestTargetRange(t *testing.T) {
 		test.CheckEqS(t, s.Name, wanted[i])
 	}
 
-	ss, err = c.FilterServers(false, []string{"s5[1]"}, []string{}, "", false)
+	ss, err = c.FilterServers(false, []string{"s5[1]"}, []string{}, "", []string{}, false)
 	test.CheckErr(t, err)
 	wanted = []string{"s5-1"}
 	test.CheckEqN(t, len(ss), len(wanted))
@@ -206,14 +249,14 @@ func TestTargetRange(t *testing.T) {
 	}
 
 	// Want errors when input malformed
-	_, err = c.FilterServers(false, []string{"s4[1"}, []string{}, "", false)
+	_, err = c.FilterServers(false, []string{"s4[1"}, []string{}, "", []string{}, false)
 	test.WantErr(t, err)
-	_, err = c.FilterServers(false, []string{"s4[1]]"}, []string{}, "", false)
+	_, err = c.FilterServers(false, []string{"s4[1]]"}, []string{}, "", []string{}, false)
 	test.WantErr(t, err)
-	_, err = c.FilterServers(false, []string{"s4[1::]"}, []string{}, "", false)
+	_, err = c.FilterServers(false, []string{"s4[1::]"}, []string{}, "", []string{}, false)
 	test.WantErr(t, err)
-	_, err = c.FilterServers(false, []string{"s4[01:a]"}, []string{}, "", false)
+	_, err = c.FilterServers(false, []string{"s4[01:a]"}, []string{}, "", []string{}, false)
 	test.WantErr(t, err)
-	_, err = c.FilterServers(false, []string{"s4[-1]"}, []string{}, "", false)
+	_, err = c.FilterServers(false, []string{"s4[-1]"}, []string{}, "", []string{}, false)
 	test.WantErr(t, err)
 }
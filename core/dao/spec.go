@@ -14,6 +14,7 @@ import (
 type Spec struct {
 	Name              string   `yaml:"_"`
 	Desc              string   `yaml:"desc"`
+	Extends           string   `yaml:"extends"`
 	Describe          bool     `yaml:"describe"`
 	ListHosts         bool     `yaml:"list_hosts"`
 	Order             string   `yaml:"order"`
@@ -36,8 +37,10 @@ type Spec struct {
 	Step              bool     `yaml:"step"`
 	Print             string   `yaml:"print"`
 
-	context     string // config path
-	contextLine int    // defined at
+	context     string     // config path
+	contextLine int        // defined at
+	node        *yaml.Node // definition, used to resolve extends
+	namespace   string     // set when imported with `as`
 }
 
 func (s *Spec) GetContext() string {
@@ -127,6 +130,7 @@ func (c *ConfigYAML) DecodeSpec(name string, specYAML yaml.Node) (*Spec, []error
 		Name:        name,
 		context:     c.Path,
 		contextLine: specYAML.Line,
+		node:        &specYAML,
 	}
 
 	specErrors := []error{}
@@ -0,0 +1,195 @@
+package dao
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/alajmo/sake/core"
+)
+
+// TagExpr is a boolean expression of tags, such as `web && prod && !canary` or
+// `(db || cache) && eu`. A single tag is also an expression.
+type TagExpr struct {
+	op   string // tag, not, and, or
+	tag  string
+	args []*TagExpr
+}
+
+// Match returns true if the tags satisfy the expression.
+func (e *TagExpr) Match(tags []string) bool {
+	switch e.op {
+	case "tag":
+		return slices.Contains(tags, e.tag)
+	case "not":
+		return !e.args[0].Match(tags)
+	case "and":
+		for _, arg := range e.args {
+			if !arg.Match(tags) {
+				return false
+			}
+		}
+		return true
+	case "or":
+		for _, arg := range e.args {
+			if arg.Match(tags) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return false
+}
+
+// Tags returns the tags used in the expression, in order of appearance.
+func (e *TagExpr) Tags() []string {
+	if e.op == "tag" {
+		return []string{e.tag}
+	}
+
+	tags := []string{}
+	for _, arg := range e.args {
+		for _, tag := range arg.Tags() {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+// ParseTagExprs parses a list of tag expressions, which are all required to match.
+func ParseTagExprs(exprs []string) (*TagExpr, error) {
+	expr := &TagExpr{op: "and"}
+	for _, e := range exprs {
+		arg, err := ParseTagExpr(e)
+		if err != nil {
+			return nil, err
+		}
+		expr.args = append(expr.args, arg)
+	}
+
+	return expr, nil
+}
+
+// ParseTagExpr parses a tag expression. Operators are, in order of precedence, `!`, `&&` and `||`,
+// and parentheses group expressions.
+func ParseTagExpr(expr string) (*TagExpr, error) {
+	p := &tagExprParser{input: expr}
+	p.next()
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.token != "" {
+		return nil, p.errorf("unexpected `%s`", p.token)
+	}
+
+	return e, nil
+}
+
+type tagExprParser struct {
+	input string
+	pos   int    // position after the current token
+	token string // current token, empty at the end of input
+	start int    // position of the current token
+}
+
+func (p *tagExprParser) errorf(format string, args ...any) error {
+	return &core.TagExprInvalid{Expr: p.input, Column: p.start + 1, Err: fmt.Sprintf(format, args...)}
+}
+
+// next reads the next token, which is an operator, a parenthesis or a tag.
+func (p *tagExprParser) next() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+	p.start = p.pos
+
+	if p.pos == len(p.input) {
+		p.token = ""
+		return
+	}
+
+	rest := p.input[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, "&&"), strings.HasPrefix(rest, "||"):
+		p.pos += 2
+	case strings.ContainsAny(rest[:1], "!()&|"):
+		p.pos += 1
+	default:
+		end := strings.IndexAny(rest, "&|!() \t")
+		if end == -1 {
+			end = len(rest)
+		}
+		p.pos += end
+	}
+
+	p.token = p.input[p.start:p.pos]
+}
+
+func (p *tagExprParser) parseOr() (*TagExpr, error) {
+	return p.parseBinary("||", "or", p.parseAnd)
+}
+
+func (p *tagExprParser) parseAnd() (*TagExpr, error) {
+	return p.parseBinary("&&", "and", p.parseUnary)
+}
+
+func (p *tagExprParser) parseBinary(token string, op string, parseArg func() (*TagExpr, error)) (*TagExpr, error) {
+	arg, err := parseArg()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []*TagExpr{arg}
+	for p.token == token {
+		p.next()
+		arg, err := parseArg()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+
+	if len(args) == 1 {
+		return args[0], nil
+	}
+
+	return &TagExpr{op: op, args: args}, nil
+}
+
+func (p *tagExprParser) parseUnary() (*TagExpr, error) {
+	switch p.token {
+	case "":
+		return nil, p.errorf("expected tag")
+	case "!":
+		p.next()
+		arg, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &TagExpr{op: "not", args: []*TagExpr{arg}}, nil
+	case "(":
+		p.next()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.token != ")" {
+			return nil, p.errorf("expected `)`")
+		}
+		p.next()
+		return e, nil
+	case ")", "&&", "||", "&", "|":
+		return nil, p.errorf("expected tag, found `%s`", p.token)
+	}
+
+	tag := p.token
+	p.next()
+
+	return &TagExpr{op: "tag", tag: tag}, nil
+}
@@ -0,0 +1,41 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/alajmo/sake/core/test"
+)
+
+func TestParseTagExpr(t *testing.T) {
+	cases := []struct {
+		expr  string
+		tags  []string
+		match bool
+	}{
+		{"web", []string{"web"}, true},
+		{"web && prod && !canary", []string{"web", "prod"}, true},
+		{"web && prod && !canary", []string{"web", "prod", "canary"}, false},
+		{"(db || cache) && eu", []string{"cache", "eu"}, true},
+		{"(db || cache) && eu", []string{"db", "us"}, false},
+		{"db || cache && eu", []string{"db"}, true},
+		{"!(db||cache)", []string{"web"}, true},
+	}
+
+	for _, c := range cases {
+		expr, err := ParseTagExpr(c.expr)
+		test.CheckErr(t, err)
+		if expr.Match(c.tags) != c.match {
+			t.Fatalf("Wanted: %s to match %q: %t", c.expr, c.tags, c.match)
+		}
+	}
+
+	expr, err := ParseTagExprs([]string{"web", "(db || cache) && !web"})
+	test.CheckErr(t, err)
+	test.CheckEqualStringArr(t, expr.Tags(), []string{"web", "db", "cache"})
+	test.CheckEqN(t, len(expr.args), 2)
+
+	for _, invalid := range []string{"", "web &&", "(web", "web)", "web & prod", "web prod"} {
+		_, err := ParseTagExpr(invalid)
+		test.IsError(t, err)
+	}
+}
@@ -2,6 +2,7 @@ package dao
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -11,18 +12,70 @@ import (
 )
 
 type Target struct {
-	Name    string   `yaml:"name"`
-	Desc    string   `yaml:"desc"`
-	All     bool     `yaml:"all"`
+	Name    string           `yaml:"name"`
+	Desc    string           `yaml:"desc"`
+	Extends string           `yaml:"extends"`
+	All     bool             `yaml:"all"`
+	Servers []string         `yaml:"servers"`
+	Tags    []string         `yaml:"tags"`
+	Regex   string           `yaml:"regex"`
+	Where   []string         `yaml:"where"`
+	AnyOf   []TargetSelector `yaml:"any_of"`
+	Exclude TargetSelector   `yaml:"exclude"`
+
+	Targets        []string       `yaml:"targets"`         // union of targets
+	ExcludeTargets []string       `yaml:"exclude_targets"` // servers of targets to remove
+	Invert         bool           `yaml:"invert"`
+	Limit          uint32         `yaml:"limit"`
+	LimitP         uint8          `yaml:"limit_p"`
+	Vars           map[string]any `yaml:"vars"`
+
+	context     string     // config path
+	contextLine int        // defined at
+	node        *yaml.Node // definition, used to resolve extends
+	namespace   string     // set when imported with `as`
+}
+
+// TargetSelector selects servers by name, tags, regex and where selectors. It's used in any_of,
+// where servers must match all of its fields, and in exclude, where servers matching any field are removed.
+type TargetSelector struct {
 	Servers []string `yaml:"servers"`
 	Tags    []string `yaml:"tags"`
 	Regex   string   `yaml:"regex"`
-	Invert  bool     `yaml:"invert"`
-	Limit   uint32   `yaml:"limit"`
-	LimitP  uint8    `yaml:"limit_p"`
+	Where   []string `yaml:"where"`
+}
+
+func (s TargetSelector) IsEmpty() bool {
+	return len(s.Servers) == 0 && len(s.Tags) == 0 && s.Regex == "" && len(s.Where) == 0
+}
+
+func (s TargetSelector) GetPrint() string {
+	parts := []string{}
+	if len(s.Servers) > 0 {
+		parts = append(parts, fmt.Sprintf("servers: %s", strings.Join(s.Servers, ", ")))
+	}
+	if len(s.Tags) > 0 {
+		parts = append(parts, fmt.Sprintf("tags: %s", strings.Join(s.Tags, ", ")))
+	}
+	if s.Regex != "" {
+		parts = append(parts, fmt.Sprintf("regex: %s", s.Regex))
+	}
+	if len(s.Where) > 0 {
+		parts = append(parts, fmt.Sprintf("where: %s", strings.Join(s.Where, ", ")))
+	}
+
+	return strings.Join(parts, "; ")
+}
 
-	context     string // config path
-	contextLine int    // defined at
+func (s TargetSelector) validate() []error {
+	errs := []error{}
+	if _, err := ParseTagExprs(s.Tags); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := ParseWheres(s.Where); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
 }
 
 func (t *Target) GetContext() string {
@@ -48,6 +101,20 @@ func (t Target) GetValue(key string, _ int) string {
 		return strings.Join(t.Tags, "\n")
 	case "regex":
 		return t.Regex
+	case "where":
+		return strings.Join(t.Where, "\n")
+	case "any_of":
+		selectors := []string{}
+		for _, s := range t.AnyOf {
+			selectors = append(selectors, s.GetPrint())
+		}
+		return strings.Join(selectors, "\n")
+	case "exclude":
+		return t.Exclude.GetPrint()
+	case "targets":
+		return strings.Join(t.Targets, "\n")
+	case "exclude_targets":
+		return strings.Join(t.ExcludeTargets, "\n")
 	case "invert":
 		return strconv.FormatBool(t.Invert)
 	case "limit":
@@ -85,6 +152,7 @@ func (c *ConfigYAML) DecodeTarget(name string, targetYAML yaml.Node) (*Target, [
 		Name:        name,
 		context:     c.Path,
 		contextLine: targetYAML.Line,
+		node:        &targetYAML,
 	}
 
 	targetErrors := []error{}
@@ -97,6 +165,11 @@ func (c *ConfigYAML) DecodeTarget(name string, targetYAML yaml.Node) (*Target, [
 		targetErrors = append(targetErrors, &core.LimitMultipleDef{Name: name})
 	}
 
+	selectors := append([]TargetSelector{{Tags: target.Tags, Where: target.Where}, target.Exclude}, target.AnyOf...)
+	for _, s := range selectors {
+		targetErrors = append(targetErrors, s.validate()...)
+	}
+
 	// Min limit-p 1
 	if target.LimitP > 100 {
 		targetErrors = append(targetErrors, &core.InvalidPercentInput{Name: "limit_p"})
@@ -115,6 +188,182 @@ func (c *Config) GetTarget(name string) (*Target, error) {
 	return nil, &core.TargetNotFound{Name: name}
 }
 
+// HasSelector returns true if the target selects servers, targets without selectors are
+// meant to be used with runtime flags.
+func (t Target) HasSelector() bool {
+	return t.hasFieldSelector() || len(t.AnyOf) > 0 || len(t.Targets) > 0
+}
+
+// tagExprs returns the tag expressions of the target, including any_of and exclude.
+func (t Target) tagExprs() []string {
+	exprs := slices.Clone(t.Tags)
+	for _, s := range t.AnyOf {
+		exprs = append(exprs, s.Tags...)
+	}
+	return append(exprs, t.Exclude.Tags...)
+}
+
+func (t Target) hasFieldSelector() bool {
+	return t.All || len(t.Servers) > 0 || len(t.Tags) > 0 || t.Regex != "" || len(t.Where) > 0
+}
+
+// FilterTarget returns the servers of a target. Servers must match all selectors of the target, where
+// any_of and targets match servers that match any of their selectors and targets. Servers matching
+// exclude and exclude_targets are then removed.
+func (c *Config) FilterTarget(t Target, invert bool) ([]Server, error) {
+	return c.filterTarget(t, invert, []string{t.Name})
+}
+
+// filterTarget resolves referenced targets recursively, path holds the targets being resolved.
+func (c *Config) filterTarget(t Target, invert bool, path []string) ([]Server, error) {
+	servers, err := c.FilterServers(t.All, t.Servers, t.Tags, t.Regex, t.Where, false)
+	if err != nil {
+		return []Server{}, err
+	}
+
+	refServers := func(name string) ([]Server, error) {
+		if slices.Contains(path, name) {
+			return []Server{}, &core.TargetCycle{Names: append(slices.Clone(path), name)}
+		}
+		ref, err := c.GetTarget(name)
+		if err != nil {
+			return []Server{}, err
+		}
+		return c.filterTarget(*ref, ref.Invert, append(slices.Clone(path), name))
+	}
+
+	if len(t.AnyOf) > 0 || len(t.Targets) > 0 {
+		anyOf := make(map[string]bool)
+		for _, s := range t.AnyOf {
+			matched, err := c.FilterServers(false, s.Servers, s.Tags, s.Regex, s.Where, false)
+			if err != nil {
+				return []Server{}, err
+			}
+			for _, server := range matched {
+				anyOf[server.Name] = true
+			}
+		}
+		for _, name := range t.Targets {
+			matched, err := refServers(name)
+			if err != nil {
+				return []Server{}, err
+			}
+			for _, server := range matched {
+				anyOf[server.Name] = true
+			}
+		}
+
+		// Without other selectors, any_of selects from all servers
+		candidates := c.Servers
+		if t.hasFieldSelector() {
+			candidates = servers
+		}
+
+		servers = []Server{}
+		for _, server := range candidates {
+			if anyOf[server.Name] {
+				servers = append(servers, server)
+			}
+		}
+	}
+
+	if !t.Exclude.IsEmpty() {
+		excluded, err := c.getExcludedServers(t.Exclude)
+		if err != nil {
+			return []Server{}, err
+		}
+		servers = GetInvertedServers(servers, excluded)
+	}
+
+	for _, name := range t.ExcludeTargets {
+		excluded, err := refServers(name)
+		if err != nil {
+			return []Server{}, err
+		}
+		servers = GetInvertedServers(servers, excluded)
+	}
+
+	if invert {
+		servers = GetInvertedServers(c.Servers, servers)
+	}
+
+	return servers, nil
+}
+
+// GetTargetsUnion returns the target with the given name, or a target that is the union of the
+// targets when several names are given.
+func (c *Config) GetTargetsUnion(names []string) (*Target, error) {
+	if len(names) == 1 {
+		return c.GetTarget(names[0])
+	}
+
+	for _, name := range names {
+		if _, err := c.GetTarget(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Target{Name: strings.Join(names, ","), Targets: names}, nil
+}
+
+// checkTargetRefs checks that the targets referenced by a target exist and are not circular.
+func (cr *ConfigResources) checkTargetRefs(t Target, path []string) error {
+	for _, name := range slices.Concat(t.Targets, t.ExcludeTargets) {
+		if slices.Contains(path, name) {
+			return &core.TargetCycle{Names: append(slices.Clone(path), name)}
+		}
+		ref, err := cr.GetTarget(name)
+		if err != nil {
+			return err
+		}
+		if err := cr.checkTargetRefs(*ref, append(slices.Clone(path), name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getExcludedServers returns servers matching any of the fields of the selector. Fields that
+// match no servers exclude nothing, only unknown server names are an error.
+func (c *Config) getExcludedServers(s TargetSelector) ([]Server, error) {
+	excluded := []Server{}
+
+	if len(s.Servers) > 0 {
+		servers, err := c.GetServersByName(s.Servers)
+		if err != nil {
+			return []Server{}, err
+		}
+		excluded = append(excluded, servers...)
+	}
+
+	if len(s.Tags) > 0 {
+		servers, err := c.matchServersByTags(s.Tags)
+		if err != nil {
+			return []Server{}, err
+		}
+		excluded = append(excluded, servers...)
+	}
+
+	if s.Regex != "" {
+		servers, err := c.matchServersByRegex(s.Regex)
+		if err != nil {
+			return []Server{}, err
+		}
+		excluded = append(excluded, servers...)
+	}
+
+	if len(s.Where) > 0 {
+		servers, err := c.matchServersByWhere(s.Where)
+		if err != nil {
+			return []Server{}, err
+		}
+		excluded = append(excluded, servers...)
+	}
+
+	return excluded, nil
+}
+
 func (c *Config) GetTargetNames() []string {
 	names := []string{}
 	for _, target := range c.Targets {
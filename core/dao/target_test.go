@@ -0,0 +1,106 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/alajmo/sake/core/test"
+)
+
+func TestFilterTarget(t *testing.T) {
+	c := Config{
+		Servers: []Server{
+			{Name: "web-1", Group: "web", Tags: []string{"web"}},
+			{Name: "web-2", Group: "web", Tags: []string{"web", "canary"}},
+			{Name: "web-3", Group: "web", Tags: []string{"web"}},
+			{Name: "db-1", Group: "db-1", Tags: []string{"db"}},
+			{Name: "db-2", Group: "db-2", Tags: []string{"db"}, User: "postgres"},
+		},
+	}
+
+	names := func(servers []Server) []string {
+		n := []string{}
+		for _, s := range servers {
+			n = append(n, s.Name)
+		}
+		return n
+	}
+
+	// All web servers plus db-1, minus web-3 and canaries
+	target := Target{
+		AnyOf:   []TargetSelector{{Tags: []string{"web"}}, {Servers: []string{"db-1"}}},
+		Exclude: TargetSelector{Servers: []string{"web-3"}, Tags: []string{"canary"}},
+	}
+	servers, err := c.FilterTarget(target, false)
+	test.CheckErr(t, err)
+	test.CheckEqualStringArr(t, names(servers), []string{"web-1", "db-1"})
+
+	servers, err = c.FilterTarget(target, true)
+	test.CheckErr(t, err)
+	test.CheckEqualStringArr(t, names(servers), []string{"web-2", "web-3", "db-2"})
+
+	// any_of is intersected with the other selectors
+	target = Target{
+		Tags:  []string{"db"},
+		AnyOf: []TargetSelector{{Where: []string{"user=postgres"}}, {Servers: []string{"web-1"}}},
+	}
+	servers, err = c.FilterTarget(target, false)
+	test.CheckErr(t, err)
+	test.CheckEqualStringArr(t, names(servers), []string{"db-2"})
+
+	// Exclude selectors that match no servers exclude nothing
+	for _, exclude := range []TargetSelector{
+		{Regex: "99.99"},
+		{Tags: []string{"missing"}},
+		{Where: []string{"user=nobody"}},
+	} {
+		servers, err = c.FilterTarget(Target{All: true, Exclude: exclude}, false)
+		test.CheckErr(t, err)
+		test.CheckEqN(t, len(servers), 5)
+	}
+
+	_, err = c.FilterTarget(Target{All: true, Exclude: TargetSelector{Servers: []string{"missing"}}}, false)
+	test.IsError(t, err)
+}
+
+func TestFilterTargetRefs(t *testing.T) {
+	c := Config{
+		Servers: []Server{
+			{Name: "eu-web", Group: "eu-web", Tags: []string{"eu", "web"}},
+			{Name: "eu-canary", Group: "eu-canary", Tags: []string{"eu", "web", "canary"}},
+			{Name: "us-web", Group: "us-web", Tags: []string{"us", "web"}},
+			{Name: "us-db", Group: "us-db", Tags: []string{"us", "db"}},
+		},
+		Targets: []Target{
+			{Name: "eu-web", Tags: []string{"eu && web"}},
+			{Name: "us-web", Tags: []string{"us && web"}},
+			{Name: "canary", Tags: []string{"canary"}},
+			{Name: "web", Targets: []string{"eu-web", "us-web"}, ExcludeTargets: []string{"canary"}},
+			{Name: "a", Targets: []string{"b"}},
+			{Name: "b", Targets: []string{"a"}},
+		},
+	}
+
+	web, err := c.GetTarget("web")
+	test.CheckErr(t, err)
+	servers, err := c.FilterTarget(*web, false)
+	test.CheckErr(t, err)
+	test.CheckEqN(t, len(servers), 2)
+	test.CheckEqS(t, servers[0].Name, "eu-web")
+	test.CheckEqS(t, servers[1].Name, "us-web")
+
+	union, err := c.GetTargetsUnion([]string{"canary", "us-web"})
+	test.CheckErr(t, err)
+	servers, err = c.FilterTarget(*union, false)
+	test.CheckErr(t, err)
+	test.CheckEqN(t, len(servers), 2)
+
+	a, err := c.GetTarget("a")
+	test.CheckErr(t, err)
+	_, err = c.FilterTarget(*a, false)
+	test.IsError(t, err)
+
+	cr := ConfigResources{Targets: c.Targets}
+	test.CheckErr(t, cr.checkTargetRefs(*web, []string{"web"}))
+	err = cr.checkTargetRefs(*a, []string{"a"})
+	test.CheckEqS(t, err.Error(), "found circular targets: a -> b -> a")
+}
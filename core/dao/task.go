@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -18,91 +19,122 @@ var REGISTER_REGEX = regexp.MustCompile("^[a-zA-Z_]+[a-zA-Z0-9_]*$")
 
 // This is the struct that is added to the Task.Tasks in import_task.go
 type TaskCmd struct {
-	ID           string
-	Name         string
-	Desc         string
-	WorkDir      string
-	Shell        string
-	RootDir      string
-	Register     string
-	Cmd          string
-	Local        bool
-	TTY          bool
-	IgnoreErrors bool
-	Envs         []string
+	ID            string
+	Name          string
+	Desc          string
+	WorkDir       string
+	Shell         string
+	RootDir       string
+	Register      string
+	Cmd           string
+	CmdTemplate   bool
+	Local         bool
+	TTY           bool
+	PTY           bool
+	LocalEnvClear bool
+	IgnoreErrors  bool
+	Envs          []string
+	Vars          map[string]any
+	Loop          *TaskLoop // var or register loop, resolved when run
 }
 
 // This is the struct that is added to the Task.TaskRefs
 type TaskRef struct {
-	Name         string
-	Desc         string
-	Cmd          string
-	WorkDir      string
-	Shell        string
-	Register     string
-	Task         string
-	Local        *bool
-	TTY          *bool
-	IgnoreErrors *bool
-	Envs         []string
+	Name          string
+	Desc          string
+	Cmd           string
+	WorkDir       string
+	Shell         string
+	Register      string
+	Task          string
+	CmdTemplate   *bool
+	Local         *bool
+	TTY           *bool
+	PTY           *bool
+	LocalEnvClear *bool
+	IgnoreErrors  *bool
+	Envs          []string
+	Vars          map[string]any
+	Loop          *TaskLoop
 }
 
 type Task struct {
-	ID      string
-	Name    string
-	Desc    string
-	TTY     bool
-	Local   bool
-	Attach  bool
-	WorkDir string
-	Shell   string
-	Envs    []string
-	Cmd     string
-	Tasks   []TaskCmd
-	Spec    Spec
-	Target  Target
-	Theme   Theme
+	ID            string
+	Name          string
+	Desc          string
+	TTY           bool
+	PTY           bool
+	Local         bool
+	LocalEnvClear bool
+	Attach        bool
+	WorkDir       string
+	Shell         string
+	Envs          []string
+	SecretEnvs    []string // names of envs and params marked as secret, including those of referenced tasks
+	Vars          map[string]any
+	Params        []TaskParam
+	Cmd           string
+	CmdTemplate   bool
+	Tasks         []TaskCmd
+	Spec          Spec
+	Target        Target
+	Theme         Theme
 
 	TaskRefs  []TaskRef
 	SpecRef   string
 	TargetRef string
 	ThemeRef  string
+	Extends   string
 
-	context     string // config path
-	contextLine int    // defined at
+	context     string     // config path
+	contextLine int        // defined at
+	node        *yaml.Node // definition, used to resolve extends
+	namespace   string     // set when imported with `as`
 }
 
 // Unmarshaled from YAML
 type TaskYAML struct {
-	Name    string        `yaml:"name"`
-	Desc    string        `yaml:"desc"`
-	Local   bool          `yaml:"local"`
-	TTY     bool          `yaml:"tty"`
-	Attach  bool          `yaml:"attach"`
-	WorkDir string        `yaml:"work_dir"`
-	Shell   string        `yaml:"shell"`
-	Cmd     string        `yaml:"cmd"`
-	Task    string        `yaml:"task"`
-	Tasks   []TaskRefYAML `yaml:"tasks"`
-	Env     yaml.Node     `yaml:"env"`
-	Spec    yaml.Node     `yaml:"spec"`
-	Target  yaml.Node     `yaml:"target"`
-	Theme   yaml.Node     `yaml:"theme"`
+	Name          string         `yaml:"name"`
+	Desc          string         `yaml:"desc"`
+	Extends       string         `yaml:"extends"`
+	Local         bool           `yaml:"local"`
+	TTY           bool           `yaml:"tty"`
+	PTY           bool           `yaml:"pty"`
+	LocalEnvClear bool           `yaml:"local_env_clear"`
+	Attach        bool           `yaml:"attach"`
+	WorkDir       string         `yaml:"work_dir"`
+	Shell         string         `yaml:"shell"`
+	Cmd           string         `yaml:"cmd"`
+	CmdTemplate   bool           `yaml:"cmd_template"`
+	Task          string         `yaml:"task"`
+	Tasks         []TaskRefYAML  `yaml:"tasks"`
+	Env           yaml.Node      `yaml:"env"`
+	Vars          map[string]any `yaml:"vars"`
+	Params        yaml.Node      `yaml:"params"`
+	Spec          yaml.Node      `yaml:"spec"`
+	Target        yaml.Node      `yaml:"target"`
+	Theme         yaml.Node      `yaml:"theme"`
 }
 
 // Unmarshaled from YAML
 type TaskRefYAML struct {
-	Name         string    `yaml:"name"`
-	Desc         string    `yaml:"desc"`
-	WorkDir      string    `yaml:"work_dir"`
-	Shell        string    `yaml:"shell"`
-	Cmd          string    `yaml:"cmd"`
-	Task         string    `yaml:"task"`
-	Register     string    `yaml:"register"`
-	Local        *bool     `yaml:"local"`
-	IgnoreErrors *bool     `yaml:"ignore_errors"`
-	TTY          *bool     `yaml:"tty"`
-	Env          yaml.Node `yaml:"env"`
+	Name          string         `yaml:"name"`
+	Desc          string         `yaml:"desc"`
+	WorkDir       string         `yaml:"work_dir"`
+	Shell         string         `yaml:"shell"`
+	Cmd           string         `yaml:"cmd"`
+	CmdTemplate   *bool          `yaml:"cmd_template"`
+	Task          string         `yaml:"task"`
+	Register      string         `yaml:"register"`
+	Local         *bool          `yaml:"local"`
+	IgnoreErrors  *bool          `yaml:"ignore_errors"`
+	TTY           *bool          `yaml:"tty"`
+	PTY           *bool          `yaml:"pty"`
+	LocalEnvClear *bool          `yaml:"local_env_clear"`
+	Env           yaml.Node      `yaml:"env"`
+	Vars          map[string]any `yaml:"vars"`
+	Loop          yaml.Node      `yaml:"loop"`
+	WithItems     yaml.Node      `yaml:"with_items"`
 }
 
 func (t Task) GetValue(key string, _ int) string {
@@ -116,6 +148,12 @@ func (t Task) GetValue(key string, _ int) string {
 		return strconv.FormatBool(t.Local)
 	case "tty":
 		return strconv.FormatBool(t.TTY)
+	case "pty":
+		return strconv.FormatBool(t.PTY)
+	case "cmd_template":
+		return strconv.FormatBool(t.CmdTemplate)
+	case "local_env_clear":
+		return strconv.FormatBool(t.LocalEnvClear)
 	case "attach":
 		return strconv.FormatBool(t.Attach)
 	case "work_dir":
@@ -159,193 +197,250 @@ func (c *ConfigYAML) ParseTasksYAML() ([]Task, []ResourceErrors[Task]) {
 	count := len(c.Tasks.Content)
 
 	taskErrors := []ResourceErrors[Task]{}
-	j := -1
 	for i := 0; i < count; i += 2 {
-		j += 1
-		task := &Task{
-			ID:          c.Tasks.Content[i].Value,
-			context:     c.Path,
-			contextLine: c.Tasks.Content[i].Line,
+		task, errs, ok := c.DecodeTask(c.Tasks.Content[i], c.Tasks.Content[i+1])
+		taskErrors = append(taskErrors, ResourceErrors[Task]{Resource: task, Errors: errs})
+
+		if ok {
+			tasks = append(tasks, *task)
 		}
-		re := ResourceErrors[Task]{Resource: task, Errors: []error{}}
-		taskErrors = append(taskErrors, re)
-		taskYAML := &TaskYAML{}
-
-		if c.Tasks.Content[i+1].Kind == 8 {
-			// Shorthand definition:
-			// ping: echo 123
-			taskYAML.Name = c.Tasks.Content[i].Value
-			taskYAML.Cmd = c.Tasks.Content[i+1].Value
-		} else {
-			// Full definition:
-			// ping:
-			//   cmd: echo 123
-			err := c.Tasks.Content[i+1].Decode(taskYAML)
+	}
 
-			if err != nil {
-				for _, yerr := range err.(*yaml.TypeError).Errors {
-					taskErrors[j].Errors = append(taskErrors[j].Errors, errors.New(yerr))
-				}
-			}
+	return tasks, taskErrors
+}
 
-			// Check that only 1 one 3 is defined (cmd, task, tasks)
-			numDefined := 0
-			if taskYAML.Cmd != "" {
-				numDefined += 1
-			}
-			if taskYAML.Task != "" {
-				numDefined += 1
-			}
-			if len(taskYAML.Tasks) > 0 {
-				numDefined += 1
-			}
-			if numDefined > 1 {
-				taskErrors[j].Errors = append(taskErrors[j].Errors, &core.TaskMultipleDef{Name: c.Tasks.Content[i].Value})
-			}
+// DecodeTask decodes a single task, ok is false if the task definition is invalid.
+func (c *ConfigYAML) DecodeTask(key *yaml.Node, value *yaml.Node) (*Task, []error, bool) {
+	task := &Task{
+		ID:          key.Value,
+		context:     c.Path,
+		contextLine: key.Line,
+		node:        value,
+	}
+	errs := []error{}
 
-			if numDefined > 1 || err != nil {
-				continue
+	taskYAML := &TaskYAML{}
+
+	if value.Kind == 8 {
+		// Shorthand definition:
+		// ping: echo 123
+		taskYAML.Name = key.Value
+		taskYAML.Cmd = value.Value
+	} else {
+		// Full definition:
+		// ping:
+		//   cmd: echo 123
+		err := value.Decode(taskYAML)
+
+		if err != nil {
+			for _, yerr := range err.(*yaml.TypeError).Errors {
+				errs = append(errs, errors.New(yerr))
 			}
 		}
 
-		if taskYAML.Name != "" {
-			task.Name = taskYAML.Name
-		} else {
-			task.Name = c.Tasks.Content[i].Value
+		// Check that only 1 one 3 is defined (cmd, task, tasks)
+		numDefined := 0
+		if taskYAML.Cmd != "" {
+			numDefined += 1
 		}
-		task.Desc = taskYAML.Desc
-		task.TTY = taskYAML.TTY
-		task.Local = taskYAML.Local
-		task.WorkDir = taskYAML.WorkDir
-		task.Shell = taskYAML.Shell
-		task.Attach = taskYAML.Attach
-
-		if !IsNullNode(taskYAML.Env) {
-			err := CheckIsMappingNode(taskYAML.Env)
-			if err != nil {
-				taskErrors[j].Errors = append(taskErrors[j].Errors, err)
-			} else {
-				task.Envs = append(task.Envs, ParseNodeEnv(taskYAML.Env)...)
-			}
+		if taskYAML.Task != "" {
+			numDefined += 1
+		}
+		if len(taskYAML.Tasks) > 0 {
+			numDefined += 1
+		}
+		if numDefined > 1 {
+			errs = append(errs, &core.TaskMultipleDef{Name: key.Value})
 		}
 
-		task.Tasks = []TaskCmd{}
-		task.TaskRefs = []TaskRef{}
-
-		// Spec
-		if len(taskYAML.Spec.Content) > 0 {
-			// Inline Spec
-			spec, specErrors := c.DecodeSpec("", taskYAML.Spec)
-			taskErrors[j].Errors = append(taskErrors[j].Errors, specErrors...)
-			task.Spec = *spec
-		} else if taskYAML.Spec.Value != "" {
-			// Spec reference
-			task.SpecRef = taskYAML.Spec.Value
-		} else {
-			task.SpecRef = DEFAULT_SPEC.Name
+		if numDefined > 1 || err != nil {
+			return task, errs, false
 		}
+	}
 
-		// Target
-		if len(taskYAML.Target.Content) > 0 {
-			// Inline Target
-			target, targetErrors := c.DecodeTarget("", taskYAML.Target)
-			taskErrors[j].Errors = append(taskErrors[j].Errors, targetErrors...)
-			task.Target = *target
-		} else if taskYAML.Target.Value != "" {
-			// Target reference
-			task.TargetRef = taskYAML.Target.Value
+	if taskYAML.Name != "" {
+		task.Name = taskYAML.Name
+	} else {
+		task.Name = key.Value
+	}
+	task.Desc = taskYAML.Desc
+	task.Extends = taskYAML.Extends
+	task.TTY = taskYAML.TTY
+	task.PTY = taskYAML.PTY
+	task.CmdTemplate = taskYAML.CmdTemplate
+	task.Vars = taskYAML.Vars
+	task.Local = taskYAML.Local
+	task.LocalEnvClear = taskYAML.LocalEnvClear
+	task.WorkDir = taskYAML.WorkDir
+	task.Shell = taskYAML.Shell
+	task.Attach = taskYAML.Attach
+
+	if !IsNullNode(taskYAML.Env) {
+		err := CheckIsMappingNode(taskYAML.Env)
+		if err != nil {
+			errs = append(errs, err)
 		} else {
-			task.TargetRef = DEFAULT_TARGET.Name
+			envs, secrets, err := ParseNodeEnv(taskYAML.Env)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			task.Envs = append(task.Envs, envs...)
+			task.addSecretEnvs(secrets)
 		}
+	}
 
-		// Theme
-		if len(taskYAML.Theme.Content) > 0 {
-			// Inline Theme
-			theme := &Theme{}
-			err := taskYAML.Theme.Decode(theme)
-			if err != nil {
-				for _, yerr := range err.(*yaml.TypeError).Errors {
-					taskErrors[j].Errors = append(taskErrors[j].Errors, errors.New(yerr))
-				}
-			} else {
-				task.Theme = *theme
+	if !IsNullNode(taskYAML.Params) {
+		params, paramErrors := ParseNodeParams(task.ID, taskYAML.Params)
+		errs = append(errs, paramErrors...)
+		task.Params = params
+		for _, param := range params {
+			if param.Secret {
+				task.addSecretEnvs([]string{param.Name})
+			}
+		}
+	}
+
+	task.Tasks = []TaskCmd{}
+	task.TaskRefs = []TaskRef{}
+
+	// Spec
+	if len(taskYAML.Spec.Content) > 0 {
+		// Inline Spec
+		spec, specErrors := c.DecodeSpec("", taskYAML.Spec)
+		errs = append(errs, specErrors...)
+		task.Spec = *spec
+	} else if taskYAML.Spec.Value != "" {
+		// Spec reference
+		task.SpecRef = taskYAML.Spec.Value
+	} else {
+		task.SpecRef = DEFAULT_SPEC.Name
+	}
+
+	// Target
+	if len(taskYAML.Target.Content) > 0 {
+		// Inline Target
+		target, targetErrors := c.DecodeTarget("", taskYAML.Target)
+		errs = append(errs, targetErrors...)
+		task.Target = *target
+	} else if taskYAML.Target.Value != "" {
+		// Target reference
+		task.TargetRef = taskYAML.Target.Value
+	} else {
+		task.TargetRef = DEFAULT_TARGET.Name
+	}
+
+	// Theme
+	if len(taskYAML.Theme.Content) > 0 {
+		// Inline Theme
+		theme := &Theme{}
+		err := taskYAML.Theme.Decode(theme)
+		if err != nil {
+			for _, yerr := range err.(*yaml.TypeError).Errors {
+				errs = append(errs, errors.New(yerr))
 			}
-		} else if taskYAML.Theme.Value != "" {
-			// Theme reference
-			task.ThemeRef = taskYAML.Theme.Value
 		} else {
-			task.ThemeRef = DEFAULT_THEME.Name
+			task.Theme = *theme
+		}
+	} else if taskYAML.Theme.Value != "" {
+		// Theme reference
+		task.ThemeRef = taskYAML.Theme.Value
+	} else {
+		task.ThemeRef = DEFAULT_THEME.Name
+	}
+
+	// Set task cmd/reference
+	if taskYAML.Task != "" {
+		// Task Reference
+		tr := TaskRef{
+			Task: taskYAML.Task,
 		}
 
-		// Set task cmd/reference
-		if taskYAML.Task != "" {
-			// Task Reference
+		task.TaskRefs = append(task.TaskRefs, tr)
+	} else if len(taskYAML.Tasks) > 0 {
+		// Tasks References
+		for k := range taskYAML.Tasks {
 			tr := TaskRef{
-				Task: taskYAML.Task,
+				Name:          taskYAML.Tasks[k].Name,
+				Desc:          taskYAML.Tasks[k].Desc,
+				WorkDir:       taskYAML.Tasks[k].WorkDir,
+				Shell:         taskYAML.Tasks[k].Shell,
+				Local:         taskYAML.Tasks[k].Local,
+				TTY:           taskYAML.Tasks[k].TTY,
+				PTY:           taskYAML.Tasks[k].PTY,
+				CmdTemplate:   taskYAML.Tasks[k].CmdTemplate,
+				LocalEnvClear: taskYAML.Tasks[k].LocalEnvClear,
+				IgnoreErrors:  taskYAML.Tasks[k].IgnoreErrors,
+				Vars:          taskYAML.Tasks[k].Vars,
 			}
 
-			task.TaskRefs = append(task.TaskRefs, tr)
-		} else if len(taskYAML.Tasks) > 0 {
-			// Tasks References
-			for k := range taskYAML.Tasks {
-				tr := TaskRef{
-					Name:         taskYAML.Tasks[k].Name,
-					Desc:         taskYAML.Tasks[k].Desc,
-					WorkDir:      taskYAML.Tasks[k].WorkDir,
-					Shell:        taskYAML.Tasks[k].Shell,
-					Local:        taskYAML.Tasks[k].Local,
-					TTY:          taskYAML.Tasks[k].TTY,
-					IgnoreErrors: taskYAML.Tasks[k].IgnoreErrors,
-					Envs:         ParseNodeEnv(taskYAML.Tasks[k].Env),
-				}
-
-				if taskYAML.Tasks[k].Register != "" {
-					match := REGISTER_REGEX.MatchString(taskYAML.Tasks[k].Register)
-					if match {
-						tr.Register = taskYAML.Tasks[k].Register
-					} else {
-						taskErrors[j].Errors = append(taskErrors[j].Errors, &core.RegisterInvalidName{Value: taskYAML.Tasks[k].Register})
-						continue
-					}
-				}
+			envs, secrets, err := ParseNodeEnv(taskYAML.Tasks[k].Env)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			tr.Envs = envs
+			task.addSecretEnvs(secrets)
 
-				// TODO: What about this?
-				// Find servers matching the flag
-				// var servers []Server
-				// for _, server := range c.Servers {
-				// 	match := pattern.MatchString(server.Host)
-				// 	if match {
-				// 		servers = append(servers, server)
-				// 	}
-				// }
-
-				// Check that only cmd or task is defined
-				if taskYAML.Tasks[k].Cmd != "" && taskYAML.Tasks[k].Task != "" {
-					taskErrors[j].Errors = append(taskErrors[j].Errors, &core.TaskRefMultipleDef{Name: c.Tasks.Content[i].Value})
-					continue
-				} else if taskYAML.Tasks[k].Cmd != "" {
-					tr.Cmd = taskYAML.Tasks[k].Cmd
-				} else if taskYAML.Tasks[k].Task != "" {
-					tr.Task = taskYAML.Tasks[k].Task
+			if taskYAML.Tasks[k].Register != "" {
+				match := REGISTER_REGEX.MatchString(taskYAML.Tasks[k].Register)
+				if match {
+					tr.Register = taskYAML.Tasks[k].Register
 				} else {
-					taskErrors[j].Errors = append(taskErrors[j].Errors, &core.NoTaskRefDefined{Name: c.Tasks.Content[i].Value})
+					errs = append(errs, &core.RegisterInvalidName{Value: taskYAML.Tasks[k].Register})
 					continue
 				}
+			}
 
-				task.TaskRefs = append(task.TaskRefs, tr)
+			loop, err := ParseNodeLoop(taskYAML.Tasks[k].Loop, taskYAML.Tasks[k].WithItems)
+			if err != nil {
+				errs = append(errs, &core.TaskRefLoopInvalid{Name: key.Value, Reason: err.Error()})
+				continue
+			}
+			tr.Loop = loop
+
+			// TODO: What about this?
+			// Find servers matching the flag
+			// var servers []Server
+			// for _, server := range c.Servers {
+			// 	match := pattern.MatchString(server.Host)
+			// 	if match {
+			// 		servers = append(servers, server)
+			// 	}
+			// }
+
+			// Check that only cmd or task is defined
+			if taskYAML.Tasks[k].Cmd != "" && taskYAML.Tasks[k].Task != "" {
+				errs = append(errs, &core.TaskRefMultipleDef{Name: key.Value})
+				continue
+			} else if taskYAML.Tasks[k].Cmd != "" {
+				tr.Cmd = taskYAML.Tasks[k].Cmd
+			} else if taskYAML.Tasks[k].Task != "" {
+				tr.Task = taskYAML.Tasks[k].Task
+			} else {
+				errs = append(errs, &core.NoTaskRefDefined{Name: key.Value})
+				continue
 			}
-		} else if taskYAML.Cmd != "" {
-			// Command
-			task.Cmd = taskYAML.Cmd
-		}
 
-		tasks = append(tasks, *task)
+			task.TaskRefs = append(task.TaskRefs, tr)
+		}
+	} else if taskYAML.Cmd != "" {
+		// Command
+		task.Cmd = taskYAML.Cmd
 	}
 
-	return tasks, taskErrors
+	return task, errs, true
 }
 
-func ParseTaskEnv(cmdEnv []string, userEnv []string, parentEnv []string, configEnv []string) ([]string, error) {
+func (t *Task) addSecretEnvs(names []string) {
+	for _, name := range names {
+		if !slices.Contains(t.SecretEnvs, name) {
+			t.SecretEnvs = append(t.SecretEnvs, name)
+		}
+	}
+}
+
+// ParseTaskEnv evaluates and merges the envs of a command, and registers the values of the envs
+// named in secrets so they're masked in output.
+func ParseTaskEnv(cmdEnv []string, userEnv []string, parentEnv []string, configEnv []string, secrets []string) ([]string, error) {
 	cmdEnv, err := EvaluateEnv(cmdEnv)
 	if err != nil {
 		return []string{}, err
@@ -357,6 +452,7 @@ func ParseTaskEnv(cmdEnv []string, userEnv []string, parentEnv []string, configE
 	}
 
 	envs := MergeEnvs(userEnv, cmdEnv, pEnv, configEnv)
+	RegisterSecretEnvs(envs, secrets)
 
 	return envs, nil
 }
@@ -366,15 +462,15 @@ func (c *Config) GetTaskServers(task *Task, runFlags *core.RunFlags, setRunFlags
 	var err error
 	// If any runtime selector flags are used, disregard config specified task targets.
 	// --invert is a modifier, not a selector, so it does not trigger this on its own.
-	if len(runFlags.Servers) > 0 || len(runFlags.Tags) > 0 || runFlags.Regex != "" || setRunFlags.All {
-		servers, err = c.FilterServers(runFlags.All, runFlags.Servers, runFlags.Tags, runFlags.Regex, runFlags.Invert)
+	if len(runFlags.Servers) > 0 || len(runFlags.Tags) > 0 || runFlags.Regex != "" || len(runFlags.Where) > 0 || setRunFlags.All {
+		servers, err = c.FilterServers(runFlags.All, runFlags.Servers, runFlags.Tags, runFlags.Regex, runFlags.Where, runFlags.Invert)
 		if err != nil {
 			return []Server{}, err
 		}
 	} else {
-		// A target named on the CLI overrides the task's config target
-		if runFlags.Target != "" {
-			target, err := c.GetTarget(runFlags.Target)
+		// Targets named on the CLI override the task's config target
+		if len(runFlags.Target) > 0 {
+			target, err := c.GetTargetsUnion(runFlags.Target)
 			if err != nil {
 				return []Server{}, err
 			}
@@ -387,7 +483,7 @@ func (c *Config) GetTaskServers(task *Task, runFlags *core.RunFlags, setRunFlags
 			invert = runFlags.Invert
 		}
 
-		servers, err = c.FilterServers(task.Target.All, task.Target.Servers, task.Target.Tags, task.Target.Regex, invert)
+		servers, err = c.FilterTarget(task.Target, invert)
 		if err != nil {
 			return []Server{}, err
 		}
@@ -505,12 +601,23 @@ const (
 	Failed
 	Ignored
 	Unreachable
+	Interrupted
 )
 
 type Report struct {
 	ReturnCode int
 	Duration   time.Duration
 	Status     TaskStatus
+
+	// Iterations of var and register loops, which are resolved per server when the task runs
+	Loop []LoopReport
+}
+
+// LoopReport is the report of a loop iteration, each item is given its own column once the task has run.
+type LoopReport struct {
+	Name   string // see LoopItemName
+	Output string // table output
+	Report
 }
 
 type ReportRow struct {
@@ -537,6 +644,8 @@ func (s TaskStatus) String() string {
 		return "ignored"
 	case Unreachable:
 		return "unreachable"
+	case Interrupted:
+		return "interrupted"
 	}
 
 	return ""
@@ -61,12 +61,15 @@ type Text struct {
 }
 
 type Theme struct {
-	Name  string `yaml:"name"`
-	Table Table  `yaml:"table"`
-	Text  Text   `yaml:"text"`
-
-	context     string // config path
-	contextLine int    // defined at
+	Name    string `yaml:"name"`
+	Extends string `yaml:"extends"`
+	Table   Table  `yaml:"table"`
+	Text    Text   `yaml:"text"`
+
+	context     string     // config path
+	contextLine int        // defined at
+	node        *yaml.Node // definition, used to resolve extends
+	namespace   string     // set when imported with `as`
 }
 
 type Row struct {
@@ -238,246 +241,255 @@ func (c *ConfigYAML) ParseThemesYAML() ([]Theme, []ResourceErrors[Theme]) {
 	count := len(c.Themes.Content)
 
 	themeErrors := []ResourceErrors[Theme]{}
-	j := -1
 	for i := 0; i < count; i += 2 {
-		j += 1
+		theme, errs := c.DecodeTheme(c.Themes.Content[i].Value, c.Themes.Content[i+1])
+		theme.contextLine = c.Themes.Content[i].Line
+		themeErrors = append(themeErrors, ResourceErrors[Theme]{Resource: theme, Errors: errs})
 
-		theme := &Theme{
-			Name:        c.Themes.Content[i].Value,
-			context:     c.Path,
-			contextLine: c.Themes.Content[i].Line,
+		if len(errs) == 0 {
+			themes = append(themes, *theme)
 		}
-		re := ResourceErrors[Theme]{Resource: theme, Errors: []error{}}
-		themeErrors = append(themeErrors, re)
+	}
 
-		err := c.Themes.Content[i+1].Decode(theme)
-		if err != nil {
-			for _, yerr := range err.(*yaml.TypeError).Errors {
-				themeErrors[j].Errors = append(themeErrors[j].Errors, errors.New(yerr))
-			}
-			continue
+	return themes, themeErrors
+}
+
+func (c *ConfigYAML) DecodeTheme(name string, themeYAML *yaml.Node) (*Theme, []error) {
+	theme := &Theme{
+		Name:        name,
+		context:     c.Path,
+		contextLine: themeYAML.Line,
+		node:        themeYAML,
+	}
+
+	themeErrors := []error{}
+	err := themeYAML.Decode(theme)
+	if err != nil {
+		for _, yerr := range err.(*yaml.TypeError).Errors {
+			themeErrors = append(themeErrors, errors.New(yerr))
 		}
+		return theme, themeErrors
+	}
+
+	setThemeDefaults(theme)
+
+	return theme, themeErrors
+}
+
+// setThemeDefaults sets default values for options that are not set
+func setThemeDefaults(theme *Theme) {
+	// TEXT
+	if theme.Text.PrefixColors == nil {
+		theme.Text.PrefixColors = DefaultText.PrefixColors
+	}
+
+	// if theme.Text.Prefix == "" {
+	// 	theme.Text.Prefix = DefaultText.Prefix
+	// }
 
-		themes = append(themes, *theme)
+	// TABLE
+	if theme.Table.Style == "connected-light" {
+		theme.Table.Box = StyleBoxLight
+	} else {
+		theme.Table.Style = "ascii"
+		theme.Table.Box = StyleBoxASCII
 	}
 
-	// Loop through themes and set default values
-	for i := range themes {
-		// TEXT
-		if themes[i].Text.PrefixColors == nil {
-			themes[i].Text.PrefixColors = DefaultText.PrefixColors
+	if theme.Table.Prefix == "" {
+		theme.Table.Prefix = DefaultTable.Prefix
+	}
+
+	if theme.Table.Options == nil {
+		theme.Table.Options = DefaultTable.Options
+	} else {
+		if theme.Table.Options.DrawBorder == nil {
+			theme.Table.Options.DrawBorder = DefaultTable.Options.DrawBorder
 		}
 
-		// if themes[i].Text.Prefix == "" {
-		// 	themes[i].Text.Prefix = DefaultText.Prefix
-		// }
+		if theme.Table.Options.SeparateColumns == nil {
+			theme.Table.Options.SeparateColumns = DefaultTable.Options.SeparateColumns
+		}
 
-		// TABLE
-		if themes[i].Table.Style == "connected-light" {
-			themes[i].Table.Box = StyleBoxLight
-		} else {
-			themes[i].Table.Style = "ascii"
-			themes[i].Table.Box = StyleBoxASCII
+		if theme.Table.Options.SeparateHeader == nil {
+			theme.Table.Options.SeparateHeader = DefaultTable.Options.SeparateHeader
 		}
 
-		if themes[i].Table.Prefix == "" {
-			themes[i].Table.Prefix = DefaultTable.Prefix
+		if theme.Table.Options.SeparateRows == nil {
+			theme.Table.Options.SeparateRows = DefaultTable.Options.SeparateRows
 		}
 
-		if themes[i].Table.Options == nil {
-			themes[i].Table.Options = DefaultTable.Options
+		if theme.Table.Options.SeparateFooter == nil {
+			theme.Table.Options.SeparateFooter = DefaultTable.Options.SeparateFooter
+		}
+	}
+
+	if theme.Table.Border == nil {
+		theme.Table.Border = DefaultTable.Border
+	} else {
+		// Header
+		if theme.Table.Border.Header == nil {
+			theme.Table.Border.Header = DefaultTable.Border.Header
 		} else {
-			if themes[i].Table.Options.DrawBorder == nil {
-				themes[i].Table.Options.DrawBorder = DefaultTable.Options.DrawBorder
+			if theme.Table.Border.Header.Fg == nil {
+				theme.Table.Border.Header.Fg = DefaultTable.Border.Header.Fg
 			}
-
-			if themes[i].Table.Options.SeparateColumns == nil {
-				themes[i].Table.Options.SeparateColumns = DefaultTable.Options.SeparateColumns
+			if theme.Table.Border.Header.Bg == nil {
+				theme.Table.Border.Header.Bg = DefaultTable.Border.Header.Bg
 			}
-
-			if themes[i].Table.Options.SeparateHeader == nil {
-				themes[i].Table.Options.SeparateHeader = DefaultTable.Options.SeparateHeader
+			if theme.Table.Border.Header.Attr == nil {
+				theme.Table.Border.Header.Attr = DefaultTable.Border.Header.Attr
 			}
+		}
 
-			if themes[i].Table.Options.SeparateRows == nil {
-				themes[i].Table.Options.SeparateRows = DefaultTable.Options.SeparateRows
+		// Row
+		if theme.Table.Border.Row == nil {
+			theme.Table.Border.Row = DefaultTable.Border.Row
+		} else {
+			if theme.Table.Border.Row.Fg == nil {
+				theme.Table.Border.Row.Fg = DefaultTable.Border.Row.Fg
+			}
+			if theme.Table.Border.Row.Bg == nil {
+				theme.Table.Border.Row.Bg = DefaultTable.Border.Row.Bg
+			}
+			if theme.Table.Border.Row.Attr == nil {
+				theme.Table.Border.Row.Attr = DefaultTable.Border.Row.Attr
 			}
+		}
 
-			if themes[i].Table.Options.SeparateFooter == nil {
-				themes[i].Table.Options.SeparateFooter = DefaultTable.Options.SeparateFooter
+		// RowAlternate
+		if theme.Table.Border.RowAlternate == nil {
+			theme.Table.Border.RowAlternate = DefaultTable.Border.RowAlternate
+		} else {
+			if theme.Table.Border.RowAlternate.Fg == nil {
+				theme.Table.Border.RowAlternate.Fg = DefaultTable.Border.RowAlternate.Fg
+			}
+			if theme.Table.Border.RowAlternate.Bg == nil {
+				theme.Table.Border.RowAlternate.Bg = DefaultTable.Border.RowAlternate.Bg
+			}
+			if theme.Table.Border.RowAlternate.Attr == nil {
+				theme.Table.Border.RowAlternate.Attr = DefaultTable.Border.RowAlternate.Attr
 			}
 		}
 
-		if themes[i].Table.Border == nil {
-			themes[i].Table.Border = DefaultTable.Border
+		// Footer
+		if theme.Table.Border.Footer == nil {
+			theme.Table.Border.Footer = DefaultTable.Border.Footer
 		} else {
-			// Header
-			if themes[i].Table.Border.Header == nil {
-				themes[i].Table.Border.Header = DefaultTable.Border.Header
-			} else {
-				if themes[i].Table.Border.Header.Fg == nil {
-					themes[i].Table.Border.Header.Fg = DefaultTable.Border.Header.Fg
-				}
-				if themes[i].Table.Border.Header.Bg == nil {
-					themes[i].Table.Border.Header.Bg = DefaultTable.Border.Header.Bg
-				}
-				if themes[i].Table.Border.Header.Attr == nil {
-					themes[i].Table.Border.Header.Attr = DefaultTable.Border.Header.Attr
-				}
-			}
-
-			// Row
-			if themes[i].Table.Border.Row == nil {
-				themes[i].Table.Border.Row = DefaultTable.Border.Row
-			} else {
-				if themes[i].Table.Border.Row.Fg == nil {
-					themes[i].Table.Border.Row.Fg = DefaultTable.Border.Row.Fg
-				}
-				if themes[i].Table.Border.Row.Bg == nil {
-					themes[i].Table.Border.Row.Bg = DefaultTable.Border.Row.Bg
-				}
-				if themes[i].Table.Border.Row.Attr == nil {
-					themes[i].Table.Border.Row.Attr = DefaultTable.Border.Row.Attr
-				}
-			}
-
-			// RowAlternate
-			if themes[i].Table.Border.RowAlternate == nil {
-				themes[i].Table.Border.RowAlternate = DefaultTable.Border.RowAlternate
-			} else {
-				if themes[i].Table.Border.RowAlternate.Fg == nil {
-					themes[i].Table.Border.RowAlternate.Fg = DefaultTable.Border.RowAlternate.Fg
-				}
-				if themes[i].Table.Border.RowAlternate.Bg == nil {
-					themes[i].Table.Border.RowAlternate.Bg = DefaultTable.Border.RowAlternate.Bg
-				}
-				if themes[i].Table.Border.RowAlternate.Attr == nil {
-					themes[i].Table.Border.RowAlternate.Attr = DefaultTable.Border.RowAlternate.Attr
-				}
-			}
-
-			// Footer
-			if themes[i].Table.Border.Footer == nil {
-				themes[i].Table.Border.Footer = DefaultTable.Border.Footer
-			} else {
-				if themes[i].Table.Border.Footer.Fg == nil {
-					themes[i].Table.Border.Footer.Fg = DefaultTable.Border.Footer.Fg
-				}
-				if themes[i].Table.Border.Footer.Bg == nil {
-					themes[i].Table.Border.Footer.Bg = DefaultTable.Border.Footer.Bg
-				}
-				if themes[i].Table.Border.Footer.Attr == nil {
-					themes[i].Table.Border.Footer.Attr = DefaultTable.Border.Footer.Attr
-				}
+			if theme.Table.Border.Footer.Fg == nil {
+				theme.Table.Border.Footer.Fg = DefaultTable.Border.Footer.Fg
+			}
+			if theme.Table.Border.Footer.Bg == nil {
+				theme.Table.Border.Footer.Bg = DefaultTable.Border.Footer.Bg
+			}
+			if theme.Table.Border.Footer.Attr == nil {
+				theme.Table.Border.Footer.Attr = DefaultTable.Border.Footer.Attr
 			}
 		}
+	}
 
-		// Title
-		if themes[i].Table.Title == nil {
-			themes[i].Table.Title = DefaultTable.Title
+	// Title
+	if theme.Table.Title == nil {
+		theme.Table.Title = DefaultTable.Title
+	} else {
+		// Header
+		if theme.Table.Title == nil {
+			theme.Table.Title = DefaultTable.Title
 		} else {
-			// Header
-			if themes[i].Table.Title == nil {
-				themes[i].Table.Title = DefaultTable.Title
-			} else {
-				if themes[i].Table.Title.Fg == nil {
-					themes[i].Table.Title.Fg = DefaultTable.Title.Fg
-				}
-				if themes[i].Table.Title.Bg == nil {
-					themes[i].Table.Title.Bg = DefaultTable.Title.Bg
-				}
-				if themes[i].Table.Title.Align == nil {
-					themes[i].Table.Title.Align = DefaultTable.Title.Align
-				}
-				if themes[i].Table.Title.Attr == nil {
-					themes[i].Table.Title.Attr = DefaultTable.Title.Attr
-				}
-				if themes[i].Table.Title.Format == nil {
-					themes[i].Table.Title.Format = DefaultTable.Title.Format
-				}
+			if theme.Table.Title.Fg == nil {
+				theme.Table.Title.Fg = DefaultTable.Title.Fg
+			}
+			if theme.Table.Title.Bg == nil {
+				theme.Table.Title.Bg = DefaultTable.Title.Bg
+			}
+			if theme.Table.Title.Align == nil {
+				theme.Table.Title.Align = DefaultTable.Title.Align
+			}
+			if theme.Table.Title.Attr == nil {
+				theme.Table.Title.Attr = DefaultTable.Title.Attr
+			}
+			if theme.Table.Title.Format == nil {
+				theme.Table.Title.Format = DefaultTable.Title.Format
 			}
 		}
+	}
 
+	// Header
+	if theme.Table.Header == nil {
+		theme.Table.Header = DefaultTable.Header
+	} else {
 		// Header
-		if themes[i].Table.Header == nil {
-			themes[i].Table.Header = DefaultTable.Header
+		if theme.Table.Header == nil {
+			theme.Table.Header = DefaultTable.Header
 		} else {
-			// Header
-			if themes[i].Table.Header == nil {
-				themes[i].Table.Header = DefaultTable.Header
-			} else {
-				if themes[i].Table.Header.Fg == nil {
-					themes[i].Table.Header.Fg = DefaultTable.Header.Fg
-				}
-				if themes[i].Table.Header.Bg == nil {
-					themes[i].Table.Header.Bg = DefaultTable.Header.Bg
-				}
-				if themes[i].Table.Header.Align == nil {
-					themes[i].Table.Header.Align = DefaultTable.Header.Align
-				}
-				if themes[i].Table.Header.Attr == nil {
-					themes[i].Table.Header.Attr = DefaultTable.Header.Attr
-				}
-				if themes[i].Table.Header.Format == nil {
-					themes[i].Table.Header.Format = DefaultTable.Header.Format
-				}
+			if theme.Table.Header.Fg == nil {
+				theme.Table.Header.Fg = DefaultTable.Header.Fg
+			}
+			if theme.Table.Header.Bg == nil {
+				theme.Table.Header.Bg = DefaultTable.Header.Bg
+			}
+			if theme.Table.Header.Align == nil {
+				theme.Table.Header.Align = DefaultTable.Header.Align
+			}
+			if theme.Table.Header.Attr == nil {
+				theme.Table.Header.Attr = DefaultTable.Header.Attr
+			}
+			if theme.Table.Header.Format == nil {
+				theme.Table.Header.Format = DefaultTable.Header.Format
 			}
 		}
+	}
 
+	// Row
+	if theme.Table.Row == nil {
+		theme.Table.Row = DefaultTable.Row
+	} else {
 		// Row
-		if themes[i].Table.Row == nil {
-			themes[i].Table.Row = DefaultTable.Row
+		if theme.Table.Row == nil {
+			theme.Table.Row = DefaultTable.Row
 		} else {
-			// Row
-			if themes[i].Table.Row == nil {
-				themes[i].Table.Row = DefaultTable.Row
-			} else {
-				if themes[i].Table.Row.Fg == nil {
-					themes[i].Table.Row.Fg = DefaultTable.Row.Fg
-				}
-				if themes[i].Table.Row.Bg == nil {
-					themes[i].Table.Row.Bg = DefaultTable.Row.Bg
-				}
-				if themes[i].Table.Row.Align == nil {
-					themes[i].Table.Row.Align = DefaultTable.Row.Align
-				}
-				if themes[i].Table.Row.Attr == nil {
-					themes[i].Table.Row.Attr = DefaultTable.Row.Attr
-				}
-				if themes[i].Table.Row.Format == nil {
-					themes[i].Table.Row.Format = DefaultTable.Row.Format
-				}
+			if theme.Table.Row.Fg == nil {
+				theme.Table.Row.Fg = DefaultTable.Row.Fg
+			}
+			if theme.Table.Row.Bg == nil {
+				theme.Table.Row.Bg = DefaultTable.Row.Bg
+			}
+			if theme.Table.Row.Align == nil {
+				theme.Table.Row.Align = DefaultTable.Row.Align
+			}
+			if theme.Table.Row.Attr == nil {
+				theme.Table.Row.Attr = DefaultTable.Row.Attr
+			}
+			if theme.Table.Row.Format == nil {
+				theme.Table.Row.Format = DefaultTable.Row.Format
 			}
 		}
+	}
 
+	// Footer
+	if theme.Table.Footer == nil {
+		theme.Table.Footer = DefaultTable.Footer
+	} else {
 		// Footer
-		if themes[i].Table.Footer == nil {
-			themes[i].Table.Footer = DefaultTable.Footer
+		if theme.Table.Footer == nil {
+			theme.Table.Footer = DefaultTable.Footer
 		} else {
-			// Footer
-			if themes[i].Table.Footer == nil {
-				themes[i].Table.Footer = DefaultTable.Footer
-			} else {
-				if themes[i].Table.Footer.Fg == nil {
-					themes[i].Table.Footer.Fg = DefaultTable.Footer.Fg
-				}
-				if themes[i].Table.Footer.Bg == nil {
-					themes[i].Table.Footer.Bg = DefaultTable.Footer.Bg
-				}
-				if themes[i].Table.Footer.Align == nil {
-					themes[i].Table.Footer.Align = DefaultTable.Footer.Align
-				}
-				if themes[i].Table.Footer.Attr == nil {
-					themes[i].Table.Footer.Attr = DefaultTable.Footer.Attr
-				}
-				if themes[i].Table.Footer.Format == nil {
-					themes[i].Table.Footer.Format = DefaultTable.Footer.Format
-				}
+			if theme.Table.Footer.Fg == nil {
+				theme.Table.Footer.Fg = DefaultTable.Footer.Fg
+			}
+			if theme.Table.Footer.Bg == nil {
+				theme.Table.Footer.Bg = DefaultTable.Footer.Bg
+			}
+			if theme.Table.Footer.Align == nil {
+				theme.Table.Footer.Align = DefaultTable.Footer.Align
+			}
+			if theme.Table.Footer.Attr == nil {
+				theme.Table.Footer.Attr = DefaultTable.Footer.Attr
+			}
+			if theme.Table.Footer.Format == nil {
+				theme.Table.Footer.Format = DefaultTable.Footer.Format
 			}
 		}
 	}
-
-	return themes, themeErrors
 }
 
 func (c *Config) GetTheme(name string) (*Theme, error) {
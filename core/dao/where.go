@@ -0,0 +1,174 @@
+package dao
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gobwas/glob"
+
+	"github.com/alajmo/sake/core"
+)
+
+// Server keys that can be used in where selectors, in addition to env.<NAME>.
+var whereKeys = []string{
+	"name", "server", "group", "desc", "host", "bastion", "user", "port", "local", "connection",
+	"container", "namespace", "shell", "work_dir", "identity_file", "tags", "extends",
+}
+
+// WhereSelector selects servers on a server field or env variable:
+//
+//	user=deploy       glob match
+//	port!=22          glob does not match
+//	env.REGION=eu-*   env variables are prefixed with env.
+//	bastion~=jump1    regex match
+type WhereSelector struct {
+	Key   string
+	Op    string // =, != or ~=
+	Value string
+
+	glob  glob.Glob
+	regex *regexp.Regexp
+}
+
+// ParseWhere parses a where selector of the form <key><op><value>.
+func ParseWhere(selector string) (*WhereSelector, error) {
+	i := strings.Index(selector, "=")
+	if i <= 0 {
+		return nil, &core.WhereInvalid{Selector: selector, Err: "expected <key>=<value>, <key>!=<value> or <key>~=<value>"}
+	}
+
+	w := &WhereSelector{Op: "=", Value: strings.TrimSpace(selector[i+1:])}
+	key := selector[:i]
+	switch key[len(key)-1] {
+	case '!':
+		w.Op, key = "!=", key[:len(key)-1]
+	case '~':
+		w.Op, key = "~=", key[:len(key)-1]
+	}
+	w.Key = strings.TrimSpace(key)
+
+	name, isEnv := strings.CutPrefix(w.Key, "env.")
+	if isEnv && name == "" || !isEnv && !slices.Contains(whereKeys, strings.ToLower(w.Key)) {
+		return nil, &core.WhereInvalid{
+			Selector: selector,
+			Err:      fmt.Sprintf("unknown key `%s`, expected env.<NAME> or one of %s", w.Key, strings.Join(whereKeys, ", ")),
+		}
+	}
+
+	var err error
+	if w.Op == "~=" {
+		w.regex, err = regexp.Compile(w.Value)
+	} else {
+		w.glob, err = glob.Compile(w.Value)
+	}
+	if err != nil {
+		return nil, &core.WhereInvalid{Selector: selector, Err: err.Error()}
+	}
+
+	return w, nil
+}
+
+// ParseWheres parses a list of where selectors.
+func ParseWheres(selectors []string) ([]*WhereSelector, error) {
+	wheres := []*WhereSelector{}
+	for _, selector := range selectors {
+		w, err := ParseWhere(selector)
+		if err != nil {
+			return nil, err
+		}
+		wheres = append(wheres, w)
+	}
+
+	return wheres, nil
+}
+
+// Match returns true if any of the values of the key matches, for != none of the values may match.
+// Bastions and tags have one value per bastion and tag.
+func (w *WhereSelector) Match(s Server) bool {
+	matched := false
+	for _, value := range s.whereValues(w.Key) {
+		if w.regex != nil && w.regex.MatchString(value) || w.glob != nil && w.glob.Match(value) {
+			matched = true
+			break
+		}
+	}
+
+	if w.Op == "!=" {
+		return !matched
+	}
+
+	return matched
+}
+
+func (s Server) whereValues(key string) []string {
+	if name, found := strings.CutPrefix(key, "env."); found {
+		// Later env variables take precedence, user arguments are appended last
+		for i := len(s.Envs) - 1; i >= 0; i-- {
+			if value, found := strings.CutPrefix(s.Envs[i], name+"="); found {
+				return []string{value}
+			}
+		}
+		return []string{""}
+	}
+
+	switch strings.ToLower(key) {
+	case "group":
+		return []string{s.Group}
+	case "bastion":
+		values := []string{}
+		for _, b := range s.Bastions {
+			values = append(values, b.GetPrint())
+		}
+		return values
+	case "tags":
+		return s.Tags
+	case "identity_file":
+		if s.IdentityFile != nil {
+			return []string{*s.IdentityFile}
+		}
+		return []string{""}
+	}
+
+	return []string{s.GetValue(key, 0)}
+}
+
+// GetServersByWhere returns servers matching all of the where selectors.
+func (c *Config) GetServersByWhere(selectors []string) ([]Server, error) {
+	servers, err := c.matchServersByWhere(selectors)
+	if err != nil {
+		return []Server{}, err
+	}
+
+	if len(servers) == 0 {
+		return []Server{}, &core.WhereNoServers{Selectors: selectors}
+	}
+
+	return servers, nil
+}
+
+// matchServersByWhere returns the servers matching all of the where selectors, without failing
+// if none match.
+func (c *Config) matchServersByWhere(selectors []string) ([]Server, error) {
+	wheres, err := ParseWheres(selectors)
+	if err != nil {
+		return []Server{}, err
+	}
+
+	var servers []Server
+	for _, server := range c.Servers {
+		matched := true
+		for _, w := range wheres {
+			if !w.Match(server) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			servers = append(servers, server)
+		}
+	}
+
+	return servers, nil
+}
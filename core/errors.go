@@ -41,6 +41,96 @@ func (c *InventoryEvalFailed) Error() string {
 	return fmt.Sprintf("failed to run inventory command %s", c.Err)
 }
 
+type InventoryInvalidJSON struct {
+	Name string
+	Err  string
+}
+
+func (c *InventoryInvalidJSON) Error() string {
+	return fmt.Sprintf("failed to parse JSON output of inventory %s: %s", c.Name, c.Err)
+}
+
+type InventoryInvalidAnsible struct {
+	Name string
+	Err  string
+}
+
+func (c *InventoryInvalidAnsible) Error() string {
+	return fmt.Sprintf("failed to parse Ansible inventory %s: %s", c.Name, c.Err)
+}
+
+type InventoryDuplicateServer struct {
+	Name   string
+	Groups []string
+}
+
+func (c *InventoryDuplicateServer) Error() string {
+	return fmt.Sprintf("server `%s` is defined more than once, in servers %s", c.Name, strings.Join(c.Groups, " and "))
+}
+
+type InventoryHostMissing struct {
+	Name  string
+	Index int
+}
+
+func (c *InventoryHostMissing) Error() string {
+	return fmt.Sprintf("missing `host` in host %d of inventory %s", c.Index, c.Name)
+}
+
+type HostRangeInvalid struct {
+	Input  string
+	Column int
+	Err    string
+}
+
+func (c *HostRangeInvalid) Error() string {
+	return fmt.Sprintf("invalid host range `%s`: %s at column %d", c.Input, c.Err, c.Column)
+}
+
+type SSHConfigNotFound struct {
+	Name string
+}
+
+func (c *SSHConfigNotFound) Error() string {
+	return fmt.Sprintf("server `%s` uses from_ssh_config, but no ssh config was found", c.Name)
+}
+
+type SSHConfigNoHosts struct {
+	Name    string
+	Pattern string
+}
+
+func (c *SSHConfigNoHosts) Error() string {
+	return fmt.Sprintf("from_ssh_config `%s` of server `%s` matched 0 hosts", c.Pattern, c.Name)
+}
+
+type TagExprInvalid struct {
+	Expr   string
+	Column int
+	Err    string
+}
+
+func (c *TagExprInvalid) Error() string {
+	return fmt.Sprintf("invalid tag expression `%s`: %s at column %d", c.Expr, c.Err, c.Column)
+}
+
+type WhereInvalid struct {
+	Selector string
+	Err      string
+}
+
+func (c *WhereInvalid) Error() string {
+	return fmt.Sprintf("invalid where selector `%s`: %s", c.Selector, c.Err)
+}
+
+type WhereNoServers struct {
+	Selectors []string
+}
+
+func (c *WhereNoServers) Error() string {
+	return fmt.Sprintf("cannot find any servers matching where `%s`", strings.Join(c.Selectors, "`, `"))
+}
+
 type TagNotFound struct {
 	Tags []string
 }
@@ -167,7 +257,171 @@ type ServerMultipleDef struct {
 }
 
 func (c *ServerMultipleDef) Error() string {
-	return fmt.Sprintf("can only define one of the following for server `%s`: host, hosts", c.Name)
+	return fmt.Sprintf("can only define one of the following for server `%s`: host, hosts, inventory, from_ssh_config", c.Name)
+}
+
+type ServerInvalidInventoryFormat struct {
+	Name   string
+	Format string
+}
+
+func (c *ServerInvalidInventoryFormat) Error() string {
+	return fmt.Sprintf("invalid inventory_format `%s` for server `%s`, valid formats are: ansible", c.Format, c.Name)
+}
+
+type ServerInvalidInventoryCacheTTL struct {
+	Name string
+	TTL  string
+}
+
+func (c *ServerInvalidInventoryCacheTTL) Error() string {
+	return fmt.Sprintf("invalid inventory_cache_ttl `%s` for server `%s`, expected a duration such as 30s, 10m or 1h", c.TTL, c.Name)
+}
+
+type ServerGroupHostDefined struct {
+	Name string
+	Key  string
+}
+
+func (c *ServerGroupHostDefined) Error() string {
+	return fmt.Sprintf("server group `%s` cannot define `%s`, hosts are defined by the servers that extend it", c.Name, c.Key)
+}
+
+type ServerGroupNotFound struct {
+	Name string
+}
+
+func (c *ServerGroupNotFound) Error() string {
+	return fmt.Sprintf("cannot find server group `%s`", c.Name)
+}
+
+type ServerInvalidSSHConfigTags struct {
+	Name string
+	Err  string
+}
+
+func (c *ServerInvalidSSHConfigTags) Error() string {
+	return fmt.Sprintf("invalid from_ssh_config_tags for server `%s`: %s", c.Name, c.Err)
+}
+
+type ServerInvalidConnection struct {
+	Name       string
+	Connection string
+}
+
+func (c *ServerInvalidConnection) Error() string {
+	return fmt.Sprintf("invalid connection `%s` for server `%s`, valid connections are: ssh, docker, kubectl", c.Connection, c.Name)
+}
+
+type SecretInvalidType struct {
+	Name string
+	Type string
+}
+
+func (c *SecretInvalidType) Error() string {
+	return fmt.Sprintf("invalid type `%s` for secret `%s`, valid types are: vault, env_file, command", c.Type, c.Name)
+}
+
+type SecretMissingField struct {
+	Name  string
+	Field string
+}
+
+func (c *SecretMissingField) Error() string {
+	return fmt.Sprintf("missing `%s` for secret `%s`", c.Field, c.Name)
+}
+
+type SecretRefInvalid struct {
+	Ref string
+}
+
+func (c *SecretRefInvalid) Error() string {
+	return fmt.Sprintf("invalid secret reference `%s`, expected secret://<provider>/<key>", c.Ref)
+}
+
+type SecretProviderNotFound struct {
+	Name string
+}
+
+func (c *SecretProviderNotFound) Error() string {
+	return fmt.Sprintf("cannot find secret provider `%s`", c.Name)
+}
+
+type SecretNotFound struct {
+	Provider string
+	Key      string
+}
+
+func (c *SecretNotFound) Error() string {
+	return fmt.Sprintf("cannot find secret `%s` in provider `%s`", c.Key, c.Provider)
+}
+
+type SecretResolveFailed struct {
+	Ref string
+	Err string
+}
+
+func (c *SecretResolveFailed) Error() string {
+	return fmt.Sprintf("failed to resolve secret `%s`: %s", c.Ref, c.Err)
+}
+
+type TaskParamInvalidName struct {
+	Task string
+	Name string
+}
+
+func (c *TaskParamInvalidName) Error() string {
+	return fmt.Sprintf("invalid param name `%s` in task `%s`, only alphanumeric characters and underscore are allowed and name cannot start with a digit", c.Name, c.Task)
+}
+
+type TaskParamInvalidRegex struct {
+	Task string
+	Name string
+	Err  string
+}
+
+func (c *TaskParamInvalidRegex) Error() string {
+	return fmt.Sprintf("invalid regex for param `%s` in task `%s`: %s", c.Name, c.Task, c.Err)
+}
+
+type TaskParamInvalidValue struct {
+	Task   string
+	Name   string
+	Value  string
+	Reason string
+}
+
+func (c *TaskParamInvalidValue) Error() string {
+	return fmt.Sprintf("invalid value `%s` for param `%s` in task `%s`, %s", c.Value, c.Name, c.Task, c.Reason)
+}
+
+type StdinTerminalCmd struct {
+	Task string
+	Cmd  string
+}
+
+func (c *StdinTerminalCmd) Error() string {
+	return fmt.Sprintf("--stdin cannot be used with command `%s` of task `%s`, since it runs with tty or pty", c.Cmd, c.Task)
+}
+
+type TaskParamMissing struct {
+	Task string
+	Name string
+}
+
+func (c *TaskParamMissing) Error() string {
+	return fmt.Sprintf("missing required param `%s` for task `%s`", c.Name, c.Task)
+}
+
+type TaskParamUnknown struct {
+	Task   string
+	Names  []string
+	Params []string
+}
+
+func (c *TaskParamUnknown) Error() string {
+	names := "`" + strings.Join(c.Names, "`, `") + "`"
+	return fmt.Sprintf("unknown params %s for task `%s`, valid params are: %s", names, c.Task, strings.Join(c.Params, ", "))
 }
 
 type ServerBastionMultipleDef struct {
@@ -194,6 +448,105 @@ func (c *NoTaskRefDefined) Error() string {
 	return fmt.Sprintf("found no `task` or `cmd` definition for sub-task in task `%s`", c.Name)
 }
 
+type TaskRefLoopInvalid struct {
+	Name   string
+	Reason string
+}
+
+func (c *TaskRefLoopInvalid) Error() string {
+	return fmt.Sprintf("invalid loop for sub-task in task `%s`: %s", c.Name, c.Reason)
+}
+
+type TaskLoopNotFound struct {
+	Kind string
+	Name string
+}
+
+func (c *TaskLoopNotFound) Error() string {
+	return fmt.Sprintf("cannot find %s `%s` to loop over", c.Kind, c.Name)
+}
+
+type TaskLoopNotList struct {
+	Name string
+}
+
+func (c *TaskLoopNotList) Error() string {
+	return fmt.Sprintf("cannot loop over var `%s`, expected list or string", c.Name)
+}
+
+type ImportGitFailed struct {
+	Source string
+	Err    string
+}
+
+func (c *ImportGitFailed) Error() string {
+	return fmt.Sprintf("failed to import `%s`: %s", c.Source, c.Err)
+}
+
+type ImportHashMismatch struct {
+	Source string
+	Wanted string
+	Found  string
+}
+
+func (c *ImportHashMismatch) Error() string {
+	return fmt.Sprintf("content of import `%s` does not match sake.lock, expected %s but found %s", c.Source, c.Wanted, c.Found)
+}
+
+type ImportPathMissing struct{}
+
+func (c *ImportPathMissing) Error() string {
+	return "missing `path` in import"
+}
+
+type ImportNamespaceInvalid struct {
+	Name string
+}
+
+func (c *ImportNamespaceInvalid) Error() string {
+	return fmt.Sprintf("invalid import namespace `%s`, `as` must be a non-empty name without whitespace", c.Name)
+}
+
+type UnknownField struct {
+	Line  int
+	Field string
+	Path  string
+}
+
+func (c *UnknownField) Error() string {
+	if c.Path == "" {
+		return fmt.Sprintf("line %d: unknown field `%s`", c.Line, c.Field)
+	}
+	return fmt.Sprintf("line %d: unknown field `%s` in `%s`", c.Line, c.Field, c.Path)
+}
+
+type ExtendsNotFound struct {
+	Kind   string
+	Name   string
+	Parent string
+}
+
+func (c *ExtendsNotFound) Error() string {
+	return fmt.Sprintf("cannot find %s `%s` extended by %s `%s`", c.Kind, c.Parent, c.Kind, c.Name)
+}
+
+type ExtendsCycle struct {
+	Kind  string
+	Names []string
+}
+
+func (c *ExtendsCycle) Error() string {
+	return fmt.Sprintf("found circular extends for %s: %s", c.Kind, strings.Join(c.Names, " -> "))
+}
+
+type TargetCycle struct {
+	Names []string
+}
+
+func (c *TargetCycle) Error() string {
+	return fmt.Sprintf("found circular targets: %s", strings.Join(c.Names, " -> "))
+}
+
 type ThemeNotFound struct {
 	Name string
 }
@@ -12,6 +12,7 @@ type ServerFlags struct {
 	Headers    []string
 	Edit       bool
 	Regex      string
+	Where      []string
 	Invert     bool
 	AllHeaders bool
 }
@@ -21,6 +22,10 @@ type TargetFlags struct {
 	Edit    bool
 }
 
+type ServerGroupFlags struct {
+	Headers []string
+}
+
 type SpecFlags struct {
 	Headers []string
 	Edit    bool
@@ -55,11 +60,12 @@ type RunFlags struct {
 	Regex   string
 	Servers []string
 	Tags    []string
+	Where   []string
 	Cwd     bool
 	Invert  bool
 	Limit   uint32
 	LimitP  uint8
-	Target  string
+	Target  []string
 	Order   string
 
 	// Config
@@ -68,8 +74,10 @@ type RunFlags struct {
 	// Task
 	Theme  string
 	TTY    bool
+	PTY    bool
 	Attach bool
 	Local  bool
+	Stdin  string
 
 	// Server
 	IdentityFile string
@@ -103,6 +111,8 @@ type SetRunFlags struct {
 	OmitEmptyColumns  bool
 	Local             bool
 	TTY               bool
+	PTY               bool
+	Stdin             bool
 	AnyErrorsFatal    bool
 	IgnoreErrors      bool
 	IgnoreUnreachable bool
@@ -114,6 +124,7 @@ type SetRunFlags struct {
 	Servers           bool
 	Tags              bool
 	Regex             bool
+	Where             bool
 	Limit             bool
 	LimitP            bool
 	Verbose           bool
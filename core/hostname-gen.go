@@ -1,8 +1,10 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -10,14 +12,14 @@ import (
 	"strings"
 )
 
-// Separate hosts with newline and space/tab
-func EvaluateInventory(
+// RunInventory runs the inventory command and returns its trimmed stdout.
+func RunInventory(
 	shell string,
 	context string,
 	input string,
 	serverEnvs []string,
 	userEnvs []string,
-) ([]string, error) {
+) (string, error) {
 	args := strings.SplitN(shell, " ", 2)
 	shellProgram := args[0]
 	shellFlag := append(args[1:], input)
@@ -27,27 +29,15 @@ func EvaluateInventory(
 	cmd.Env = append(cmd.Env, userEnvs...)
 
 	cmd.Dir = filepath.Dir(context)
-	out, err := cmd.CombinedOutput()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
 
 	if err != nil {
-		return []string{}, &InventoryEvalFailed{Err: string(out)}
+		return "", &InventoryEvalFailed{Err: string(out) + stderr.String()}
 	}
 
-	trimmedOutput := strings.TrimSpace(string(out))
-	trimmedOutput = strings.Trim(trimmedOutput, "\t")
-	trimmedOutput = strings.Trim(trimmedOutput, "\r")
-	trimmedOutput = strings.TrimPrefix(trimmedOutput, "\n")
-	trimmedOutput = strings.TrimSuffix(trimmedOutput, "\n")
-
-	output := strings.Fields(trimmedOutput)
-	hosts := []string{}
-	for _, h := range output {
-		if h != "" {
-			hosts = append(hosts, h)
-		}
-	}
-
-	return hosts, nil
+	return strings.TrimSpace(string(out)), nil
 }
 
 type HostPart struct {
@@ -59,18 +49,33 @@ type HostString struct {
 	Value string
 }
 
+// HostRange is a single value, or a range of numbers or letters, for instance 1, 1:20:2, 7-9 or a:f.
 type HostRange struct {
 	Start string
 	End   string
 	Step  string
 }
 
+// HostList is a comma separated list of values and ranges within brackets, for instance [1,3,7-9].
+type HostList struct {
+	Ranges []HostRange
+
+	start int // index of [
+	end   int // index after ]
+}
+
+// EvaluateRange expands the ranges in input, for instance web-[1:20:2], rack-[a:f],
+// db-[1,3,7-9] and 10.0.[1:3].[10:20].
 func EvaluateRange(input string) ([]string, error) {
 	ast, err := buildRangeAST(input)
 	if err != nil {
 		return []string{}, err
 	}
 
+	if err := checkOctets(input, ast); err != nil {
+		return []string{}, err
+	}
+
 	hosts, err := buildHosts(ast)
 	if err != nil {
 		return []string{}, err
@@ -85,7 +90,7 @@ func buildRangeAST(input string) ([]any, error) {
 	i := 0
 	for i < len(input) {
 		if string(input[i]) == "[" {
-			r, j, err := readRange(input, i+1)
+			r, j, err := readRange(input, i)
 			if err != nil {
 				return []any{}, err
 			}
@@ -121,10 +126,14 @@ func buildHosts(parts []any) ([]string, error) {
 			for j := 0; j < len(hosts); j++ {
 				hosts[j] += v.Value
 			}
-		case HostRange:
-			subs, err := expandRange(v)
-			if err != nil {
-				return []string{}, err
+		case HostList:
+			var subs = []string{}
+			for _, r := range v.Ranges {
+				s, err := expandRange(r)
+				if err != nil {
+					return []string{}, err
+				}
+				subs = append(subs, s...)
 			}
 			var temp = []string{}
 			for j := 0; j < len(subs); j++ {
@@ -139,100 +148,212 @@ func buildHosts(parts []any) ([]string, error) {
 	return hosts, nil
 }
 
-type RangeState uint64
+func rangeError(input string, i int, format string, a ...any) error {
+	return &HostRangeInvalid{Input: input, Column: i + 1, Err: fmt.Sprintf(format, a...)}
+}
 
-const (
-	Start = 0
-	End   = 1
-	Step  = 2
-)
+// readRange reads the list starting at the [ at index i, and returns the index after the closing ].
+func readRange(input string, i int) (HostList, int, error) {
+	list := HostList{start: i}
 
-func readRange(input string, i int) (HostRange, int, error) {
-	r := HostRange{
-		Start: "",
-		End:   "",
-		Step:  "1",
-	}
+	j := i + 1
+	for {
+		k := j
+		for k < len(input) && input[k] != ',' && input[k] != ']' {
+			if input[k] == '[' {
+				return HostList{}, k, rangeError(input, k, "unexpected [ in range")
+			}
+			k += 1
+		}
 
-	var state RangeState
-	for i < len(input) {
-		if state > 2 {
-			// malformed range
-			return HostRange{}, i, errors.New("parsing hosts failed")
+		if k == len(input) {
+			return HostList{}, k, rangeError(input, i, "missing closing ]")
+		}
+
+		r, err := readRangeItem(input, j, k)
+		if err != nil {
+			return HostList{}, k, err
 		}
+		list.Ranges = append(list.Ranges, r)
 
-		if string(input[i]) == "]" {
-			// end of range
-			i += 1
+		j = k + 1
+		if input[k] == ']' {
 			break
 		}
+	}
 
-		if IsDigit(string(input[i])) {
-			switch state {
-			case Start:
-				r.Start += string(input[i])
-			case End:
-				r.End += string(input[i])
-			case Step:
-				r.Step = string(input[i])
-			}
-		} else if string(input[i]) == ":" {
-			state += 1
-		} else {
-			return HostRange{}, i, fmt.Errorf("parsing hosts failed, found %s in range", string(input[i]))
+	list.end = j
+	return list, j, nil
+}
+
+// readRangeItem reads a value or range of the form <start>:<end>[:<step>] or <start>-<end>
+// between index i and j.
+func readRangeItem(input string, i int, j int) (HostRange, error) {
+	item := input[i:j]
+	if item == "" {
+		return HostRange{}, rangeError(input, i, "missing value")
+	}
+
+	sep := ":"
+	if !strings.Contains(item, ":") && strings.Contains(item, "-") {
+		sep = "-"
+	}
+
+	fields := strings.Split(item, sep)
+	if sep == "-" && len(fields) > 2 || len(fields) > 3 {
+		n := len(fields[0]) + 1 + len(fields[1])
+		if sep == ":" {
+			n += 1 + len(fields[2])
 		}
+		return HostRange{}, rangeError(input, i+n, "unexpected %s, expected <start>:<end>:<step> or <start>-<end>", sep)
+	}
 
-		i += 1
+	// Column of each field
+	columns := []int{i}
+	for k := 1; k < len(fields); k++ {
+		columns = append(columns, columns[k-1]+len(fields[k-1])+1)
 	}
 
-	if r.Start == "" {
-		return HostRange{}, i, errors.New("parsing hosts failed, missing start range")
+	names := []string{"start", "end", "step"}
+	for k, field := range fields {
+		if field == "" {
+			return HostRange{}, rangeError(input, columns[k], "missing %s", names[k])
+		}
+
+		isNumber := IsDigit(field)
+		if k == 2 && !isNumber {
+			return HostRange{}, rangeError(input, columns[k], "step must be a number")
+		}
+
+		if !isNumber && !(len(field) == 1 && isLetter(field[0])) {
+			return HostRange{}, rangeError(input, columns[k], "%s must be a number or a letter, found %s", names[k], field)
+		}
 	}
 
-	if r.End == "" {
-		return HostRange{}, i, errors.New("parsing hosts failed, missing end range")
+	r := HostRange{Start: fields[0], End: fields[0], Step: "1"}
+	if len(fields) == 1 {
+		return r, nil
 	}
 
-	s, err := strconv.Atoi(r.Start)
-	if err != nil {
-		return HostRange{}, i, errors.New("start is not a number")
+	r.End = fields[1]
+	if len(fields) == 3 {
+		r.Step = fields[2]
 	}
 
-	e, err := strconv.Atoi(r.End)
-	if err != nil {
-		return HostRange{}, i, errors.New("end is not a number")
+	startNumber, endNumber := IsDigit(r.Start), IsDigit(r.End)
+	if startNumber != endNumber {
+		return HostRange{}, rangeError(input, columns[1], "start and end must both be numbers or letters")
 	}
 
-	if s > e {
-		return HostRange{}, i, errors.New("parsing hosts failed, start cannot be greater than end")
+	if !startNumber && isLower(r.Start[0]) != isLower(r.End[0]) {
+		return HostRange{}, rangeError(input, columns[1], "start and end must be letters of the same case")
 	}
 
-	return r, i, nil
+	step, err := strconv.Atoi(r.Step)
+	if err != nil || step <= 0 {
+		return HostRange{}, rangeError(input, columns[2], "step less than 1")
+	}
+
+	if startNumber {
+		s, err := strconv.Atoi(r.Start)
+		if err != nil {
+			return HostRange{}, rangeError(input, columns[0], "start is not a number")
+		}
+
+		e, err := strconv.Atoi(r.End)
+		if err != nil {
+			return HostRange{}, rangeError(input, columns[1], "end is not a number")
+		}
+
+		if s > e {
+			return HostRange{}, rangeError(input, columns[0], "start cannot be greater than end")
+		}
+	} else if r.Start[0] > r.End[0] {
+		return HostRange{}, rangeError(input, columns[0], "start cannot be greater than end")
+	}
+
+	return r, nil
+}
+
+// checkOctets checks that ranges spanning a whole octet of an IPv4 address, such as
+// user@10.0.[1:3].[10:20]:22, are within 0-255.
+func checkOctets(input string, parts []any) error {
+	sample := ""
+	for _, part := range parts {
+		switch v := part.(type) {
+		case HostString:
+			sample += v.Value
+		case HostList:
+			sample += "0"
+		}
+	}
+
+	if i := strings.LastIndex(sample, "@"); i >= 0 {
+		sample = sample[i+1:]
+	}
+	if i := strings.LastIndex(sample, ":"); i >= 0 {
+		sample = sample[:i]
+	}
+
+	ip := net.ParseIP(sample)
+	if ip == nil || ip.To4() == nil || strings.Count(sample, ".") != 3 {
+		return nil
+	}
+
+	for _, part := range parts {
+		list, ok := part.(HostList)
+		if !ok {
+			continue
+		}
+
+		startOfOctet := list.start == 0 || strings.ContainsRune(".@", rune(input[list.start-1]))
+		endOfOctet := list.end == len(input) || strings.ContainsRune(".:", rune(input[list.end]))
+		if !startOfOctet || !endOfOctet {
+			continue
+		}
+
+		for _, r := range list.Ranges {
+			if e, err := strconv.Atoi(r.End); err == nil && e > 255 {
+				return rangeError(input, list.start, "octet %s is greater than 255", r.End)
+			}
+		}
+	}
+
+	return nil
 }
 
 func expandRange(hr HostRange) ([]string, error) {
-	padLen, err := countPadding(hr.Start)
+	dStep, err := strconv.Atoi(hr.Step)
 	if err != nil {
 		return []string{}, err
 	}
 
-	dStart, err := strconv.Atoi(hr.Start)
+	if dStep <= 0 {
+		return []string{}, errors.New("parsing hosts failed, step less than 1")
+	}
+
+	if !IsDigit(hr.Start) {
+		hosts := []string{}
+		for c := int(hr.Start[0]); c <= int(hr.End[0]); c += dStep {
+			hosts = append(hosts, string(rune(c)))
+		}
+		return hosts, nil
+	}
+
+	padLen, err := countPadding(hr.Start)
 	if err != nil {
 		return []string{}, err
 	}
-	dEnd, err := strconv.Atoi(hr.End)
+
+	dStart, err := strconv.Atoi(hr.Start)
 	if err != nil {
 		return []string{}, err
 	}
-	dStep, err := strconv.Atoi(hr.Step)
+	dEnd, err := strconv.Atoi(hr.End)
 	if err != nil {
 		return []string{}, err
 	}
 
-	if dStep <= 0 {
-		return []string{}, errors.New("parsing hosts failed, step less than 1")
-	}
-
 	if dEnd < dStart {
 		return []string{}, errors.New("parsing hosts failed, end lower than start")
 	}
@@ -256,6 +377,14 @@ func expandRange(hr HostRange) ([]string, error) {
 	return hosts, nil
 }
 
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
 func countPadding(s string) (int, error) {
 	origLen := len(s)
 	digit, err := strconv.Atoi(s)
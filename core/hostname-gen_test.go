@@ -1,47 +1,26 @@
 package core
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/alajmo/sake/core/test"
 )
 
-func TestEvaluateInventory(t *testing.T) {
-	// IP inventory 1 host
-	input := `echo 192.168.0.1`
-	hosts, err := EvaluateInventory("sh -c", "", input, []string{}, []string{})
+func TestRunInventory(t *testing.T) {
+	// Output is trimmed
+	out, err := RunInventory("sh -c", "", `printf "\n192.168.0.1\t192.168.0.2\n\n"`, []string{}, []string{})
 	test.CheckErr(t, err)
-	wanted := []string{"192.168.0.1"}
-	for i := range wanted {
-		test.CheckEqS(t, hosts[i], wanted[i])
-	}
+	test.CheckEqS(t, out, "192.168.0.1\t192.168.0.2")
 
-	// IP inventory 2 hosts splitted on space
-	input = `echo "192.168.0.1 192.168.0.2"`
-	hosts, err = EvaluateInventory("sh -c", "", input, []string{}, []string{})
+	// Envs are passed to the command
+	out, err = RunInventory("sh -c", "", `echo "$HOST $ENV"`, []string{"HOST=192.168.0.1"}, []string{"ENV=prod"})
 	test.CheckErr(t, err)
-	wanted = []string{"192.168.0.1", "192.168.0.2"}
-	for i := range wanted {
-		test.CheckEqS(t, hosts[i], wanted[i])
-	}
-
-	// IP inventory 2 hosts splitted on newline
-	input = `echo "192.168.0.1\n192.168.0.2"`
-	hosts, err = EvaluateInventory("sh -c", "", input, []string{}, []string{})
-	test.CheckErr(t, err)
-	wanted = []string{"192.168.0.1", "192.168.0.2"}
-	for i := range wanted {
-		test.CheckEqS(t, hosts[i], wanted[i])
-	}
+	test.CheckEqS(t, out, "192.168.0.1 prod")
 
-	// IP inventory 2 hosts splitted on tab
-	input = `echo "192.168.0.1\t192.168.0.2"`
-	hosts, err = EvaluateInventory("sh -c", "", input, []string{}, []string{})
-	test.CheckErr(t, err)
-	wanted = []string{"192.168.0.1", "192.168.0.2"}
-	for i := range wanted {
-		test.CheckEqS(t, hosts[i], wanted[i])
-	}
+	// Failing command
+	_, err = RunInventory("sh -c", "", `echo failed >&2; exit 1`, []string{}, []string{})
+	test.IsError(t, err)
 }
 
 func TestEvaluateRange(t *testing.T) {
@@ -115,12 +94,44 @@ func TestEvaluateRange(t *testing.T) {
 		test.CheckEqS(t, hosts[i], wanted[i])
 	}
 
-	// Malformed ranges
-	_, err = EvaluateRange("192.[2:1].0")
-	test.IsError(t, err)
+	// Steps, letters, lists and IPv4 octets
+	cases := map[string][]string{
+		"192.[0].0":             {"192.0.0"},
+		"web-[1:20:8]":          {"web-1", "web-9", "web-17"},
+		"rack-[a:c]":            {"rack-a", "rack-b", "rack-c"},
+		"rack-[A:E:2]":          {"rack-A", "rack-C", "rack-E"},
+		"db-[1,3,7-9]":          {"db-1", "db-3", "db-7", "db-8", "db-9"},
+		"db-[01-02,x]":          {"db-01", "db-02", "db-x"},
+		"10.0.[1:2].[10,20]":    {"10.0.1.10", "10.0.2.10", "10.0.1.20", "10.0.2.20"},
+		"u@10.[254:255].0.1:22": {"u@10.254.0.1:22", "u@10.255.0.1:22"},
+	}
+	for input, wanted := range cases {
+		hosts, err = EvaluateRange(input)
+		test.CheckErr(t, err)
+		test.CheckEqualStringArr(t, hosts, wanted)
+	}
 
-	_, err = EvaluateRange("192.[0].0")
-	test.IsError(t, err)
+	// Malformed ranges, errors point at the column
+	errorCases := map[string]int{
+		"192.[2:1].0":    6,
+		"web-[1:5:0]":    10,
+		"rack-[a:F]":     9,
+		"rack-[a:5]":     9,
+		"db-[1,,3]":      7,
+		"db-[1,3-]":      9,
+		"db-[ab]":        5,
+		"10.0.[1:256].1": 6,
+		"web-[1:2":       5,
+		"web-[1:2[3]]":   9,
+	}
+	for input, column := range errorCases {
+		_, err = EvaluateRange(input)
+		var rangeErr *HostRangeInvalid
+		if !errors.As(err, &rangeErr) {
+			t.Fatalf("Wanted: range error for %q, Found: %v", input, err)
+		}
+		test.CheckEqN(t, rangeErr.Column, column)
+	}
 
 	_, err = EvaluateRange("192.[0.0")
 	test.IsError(t, err)
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+const SecretMask = "***"
+
+// Shorter secret values are not masked, since masking them would garble unrelated output.
+const MinSecretLength = 4
+
+// Time after which data held back by MaskReader is passed on if no more data arrives, so
+// interactive prompts aren't stalled.
+const maskFlushDelay = 100 * time.Millisecond
+
+var secrets = struct {
+	sync.RWMutex
+	values []string
+	short  []string // values not masked, to warn only once per value
+}{}
+
+// Warnings about secrets that are not masked are written here.
+var maskWarnings io.Writer = os.Stderr
+
+// AddSecret registers a resolved secret value, which is then masked by MaskSecrets.
+func AddSecret(value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+
+	secrets.Lock()
+	defer secrets.Unlock()
+
+	if len(value) < MinSecretLength {
+		if !StringInSlice(value, secrets.short) {
+			secrets.short = append(secrets.short, value)
+			fmt.Fprintf(maskWarnings, "%s: secret value shorter than %d characters is not masked in output\n", text.FgYellow.Sprintf("warning"), MinSecretLength)
+		}
+		return
+	}
+
+	if StringInSlice(value, secrets.values) {
+		return
+	}
+
+	// Longest first, so a secret that contains another secret is masked as a whole
+	secrets.values = append(secrets.values, value)
+	sort.SliceStable(secrets.values, func(i, j int) bool {
+		return len(secrets.values[i]) > len(secrets.values[j])
+	})
+}
+
+// MaskSecrets replaces all registered secret values in s.
+func MaskSecrets(s string) string {
+	secrets.RLock()
+	defer secrets.RUnlock()
+
+	for _, value := range secrets.values {
+		s = strings.ReplaceAll(s, value, SecretMask)
+	}
+
+	return s
+}
+
+// MaskReader masks registered secret values in the data read from the underlying reader.
+// Data that could be the start of a secret is held back until the secret can be ruled out,
+// so secrets split across reads are masked as well. Held back data is passed on once no more
+// data arrives within maskFlushDelay, for instance when the command waits for input.
+type MaskReader struct {
+	reader  io.Reader
+	reads   chan maskRead
+	pending string
+	out     []byte
+	err     error
+}
+
+type maskRead struct {
+	data []byte
+	err  error
+}
+
+func NewMaskReader(r io.Reader) *MaskReader {
+	return &MaskReader{reader: r}
+}
+
+func (r *MaskReader) Read(p []byte) (int, error) {
+	if r.reads == nil {
+		r.reads = make(chan maskRead)
+		go r.readAll()
+	}
+
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		var read maskRead
+		if r.pending == "" {
+			read = <-r.reads
+		} else {
+			select {
+			case read = <-r.reads:
+			case <-time.After(maskFlushDelay):
+				r.out = []byte(r.pending)
+				r.pending = ""
+				continue
+			}
+		}
+
+		masked := MaskSecrets(r.pending + string(read.data))
+		if err := read.err; err != nil {
+			r.err = err
+			r.out = []byte(masked)
+			r.pending = ""
+			continue
+		}
+
+		held := secretPrefixLen(masked)
+		r.out = []byte(masked[:len(masked)-held])
+		r.pending = masked[len(masked)-held:]
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// readAll reads from the underlying reader until it fails, so Read can wait for data with a timeout.
+func (r *MaskReader) readAll() {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.reader.Read(buf)
+		r.reads <- maskRead{data: append([]byte(nil), buf[:n]...), err: err}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// secretPrefixLen returns the length of the longest suffix of s that is the start of a secret.
+func secretPrefixLen(s string) int {
+	secrets.RLock()
+	defer secrets.RUnlock()
+
+	var held int
+	for _, value := range secrets.values {
+		for k := min(len(value)-1, len(s)); k > held; k-- {
+			if strings.HasSuffix(s, value[:k]) {
+				held = k
+				break
+			}
+		}
+	}
+
+	return held
+}
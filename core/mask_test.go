@@ -0,0 +1,80 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestMaskReader(t *testing.T) {
+	AddSecret("hunter2")
+	AddSecret("s3cr3t-token")
+
+	input := "password: hunter2\ntoken: s3cr3t-token, hunt\n"
+	wanted := "password: ***\ntoken: ***, hunt\n"
+
+	// Read one byte at a time, so secrets are split across reads
+	out, err := io.ReadAll(NewMaskReader(iotest.OneByteReader(strings.NewReader(input))))
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+
+	if string(out) != wanted {
+		t.Fatalf(`Wanted: %q, Found: %q`, wanted, out)
+	}
+}
+
+func TestMaskReaderFlush(t *testing.T) {
+	AddSecret("hunter2")
+
+	r, w := io.Pipe()
+	defer w.Close()
+	go func() {
+		_, _ = w.Write([]byte("Password for hun"))
+	}()
+
+	// The trailing start of a secret is passed on once no more data arrives
+	reader := NewMaskReader(r)
+	var out []byte
+	deadline := time.Now().Add(2 * time.Second)
+	for string(out) != "Password for hun" && time.Now().Before(deadline) {
+		b := make([]byte, 64)
+		n, err := reader.Read(b)
+		if err != nil {
+			t.Fatalf("%q", err)
+		}
+		out = append(out, b[:n]...)
+	}
+
+	if string(out) != "Password for hun" {
+		t.Fatalf(`Wanted: %q, Found: %q`, "Password for hun", out)
+	}
+}
+
+func TestAddSecretMinLength(t *testing.T) {
+	var warnings bytes.Buffer
+	maskWarnings = &warnings
+	t.Cleanup(func() { maskWarnings = os.Stderr })
+
+	AddSecret("a")
+	AddSecret(" 12 ")
+	AddSecret("12")
+	AddSecret("")
+
+	input := "a value of 12"
+	if masked := MaskSecrets(input); masked != input {
+		t.Fatalf(`Wanted: %q, Found: %q`, input, masked)
+	}
+
+	// Skipped values are warned about once, without printing them
+	if n := strings.Count(warnings.String(), "warning"); n != 2 {
+		t.Fatalf("Wanted: 2 warnings, Found: %q", warnings.String())
+	}
+	if strings.Contains(warnings.String(), "12") {
+		t.Fatalf("Wanted: warning without the secret value, Found: %q", warnings.String())
+	}
+}
@@ -12,6 +12,7 @@ var FailedPrint = text.Colors{text.Reset, text.FgRed}
 var SkippedPrint = text.Colors{text.Reset, text.FgBlue}
 var IgnoredPrint = text.Colors{text.Reset, text.FgMagenta}
 var UnreachablePrint = text.Colors{text.Reset, text.FgRed}
+var InterruptedPrint = text.Colors{text.Reset, text.FgYellow}
 var ZeroPrint = text.Colors{text.Reset, text.Faint}
 
 // Format map against go-pretty/table
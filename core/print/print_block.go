@@ -2,7 +2,10 @@ package print
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/alajmo/sake/core"
@@ -44,6 +47,7 @@ func PrintServerBlocks(servers []dao.Server) {
 		if server.Name != server.Group {
 			output += printStringField("group", server.Group, false)
 		}
+		output += printStringField("extends", server.Extends, false)
 		output += printStringField("desc", server.Desc, false)
 		output += printStringField("user", server.User, false)
 		output += printStringField("host", server.Host, false)
@@ -53,6 +57,11 @@ func PrintServerBlocks(servers []dao.Server) {
 		}
 
 		output += printBoolField("local", server.Local, false)
+		if server.Connection != dao.ConnectionSSH {
+			output += printStringField("connection", server.Connection, false)
+			output += printStringField("container", server.Container, false)
+			output += printStringField("namespace", server.Namespace, false)
+		}
 		output += printStringField("shell", server.Shell, false)
 		output += printStringField("work_dir", server.WorkDir, false)
 		output += printSliceField("tags", server.Tags, false)
@@ -61,7 +70,11 @@ func PrintServerBlocks(servers []dao.Server) {
 
 		envs := server.GetNonDefaultEnvs()
 		if envs != nil {
-			printEnv(envs)
+			printEnv(envs, server.SecretEnvs)
+		}
+
+		if len(server.Vars) > 0 {
+			printVars(server.Vars)
 		}
 
 		if i < len(servers)-1 {
@@ -94,6 +107,9 @@ func PrintTaskBlock(tasks []dao.Task) {
 		output += printStringField("work_dir", task.WorkDir, false)
 		output += printBoolField("local", task.Local, false)
 		output += printBoolField("tty", task.TTY, false)
+		output += printBoolField("pty", task.PTY, false)
+		output += printBoolField("cmd_template", task.CmdTemplate, false)
+		output += printBoolField("local_env_clear", task.LocalEnvClear, false)
 		output += printBoolField("attach", task.Attach, false)
 
 		fmt.Print(output)
@@ -102,7 +118,15 @@ func PrintTaskBlock(tasks []dao.Task) {
 		PrintTargetBlocks([]dao.Target{task.Target}, true)
 
 		if task.Envs != nil {
-			printEnv(task.Envs)
+			printEnv(task.Envs, task.SecretEnvs)
+		}
+
+		if len(task.Vars) > 0 {
+			printVars(task.Vars)
+		}
+
+		if len(task.Params) > 0 {
+			printParams(task.Params)
 		}
 
 		if task.Cmd != "" {
@@ -133,6 +157,33 @@ func PrintTaskBlock(tasks []dao.Task) {
 	}
 }
 
+func PrintServerGroupBlocks(groups []dao.ServerGroup) {
+	if len(groups) == 0 {
+		return
+	}
+
+	for i, group := range groups {
+		output := ""
+		output += printStringField("name", group.Name, false)
+		output += printStringField("desc", group.Desc, false)
+		output += printStringField("extends", group.Extends, false)
+		output += printStringField("user", group.User, false)
+		output += printNumberField("port", int(group.Port), false)
+		output += printSliceField("bastion", group.Bastion, false)
+		output += printSliceField("tags", group.Tags, false)
+		output += printSliceField("servers", group.Servers, false)
+		fmt.Print(output)
+
+		if len(group.Envs) > 0 {
+			printEnv(group.Envs, group.SecretEnvs)
+		}
+
+		if i < len(groups)-1 {
+			fmt.Print("\n--\n\n")
+		}
+	}
+}
+
 func PrintTargetBlocks(targets []dao.Target, indent bool) {
 	if len(targets) == 0 {
 		return
@@ -146,6 +197,15 @@ func PrintTargetBlocks(targets []dao.Target, indent bool) {
 		output += printSliceField("servers", target.Servers, indent)
 		output += printStringField("regex", target.Regex, indent)
 		output += printSliceField("tags", target.Tags, indent)
+		output += printSliceField("where", target.Where, indent)
+		anyOf := []string{}
+		for _, s := range target.AnyOf {
+			anyOf = append(anyOf, s.GetPrint())
+		}
+		output += printSliceField("any_of", anyOf, indent)
+		output += printStringField("exclude", target.Exclude.GetPrint(), indent)
+		output += printSliceField("targets", target.Targets, indent)
+		output += printSliceField("exclude_targets", target.ExcludeTargets, indent)
 		output += printNumberField("limit", int(target.Limit), indent)
 		output += printNumberField("limit_p", int(target.LimitP), indent)
 
@@ -214,16 +274,66 @@ func PrintSpecBlocks(specs []dao.Spec, indent bool) {
 }
 
 func printCmd(cmd string) {
-	scanner := bufio.NewScanner(strings.NewReader(cmd))
+	scanner := bufio.NewScanner(strings.NewReader(core.MaskSecrets(cmd)))
 	for scanner.Scan() {
 		fmt.Printf("%4s%s\n", " ", scanner.Text())
 	}
 }
 
-func printEnv(env []string) {
+func printEnv(env []string, secrets []string) {
 	fmt.Printf("env: \n")
 	for _, env := range env {
-		fmt.Printf("%4s%s\n", " ", strings.Replace(strings.TrimSuffix(env, "\n"), "=", ": ", 1))
+		name, value, _ := strings.Cut(strings.TrimSuffix(env, "\n"), "=")
+		if slices.Contains(secrets, name) && !dao.IsSecretRef(value) {
+			value = core.SecretMask
+		}
+		fmt.Printf("%4s%s: %s\n", " ", name, core.MaskSecrets(value))
+	}
+}
+
+func printVars(vars map[string]any) {
+	fmt.Printf("vars: \n")
+	keys := slices.Sorted(maps.Keys(vars))
+	for _, key := range keys {
+		value := fmt.Sprint(vars[key])
+		switch vars[key].(type) {
+		case []any, map[string]any:
+			if b, err := json.Marshal(vars[key]); err == nil {
+				value = string(b)
+			}
+		}
+		fmt.Printf("%4s%s: %s\n", " ", key, value)
+	}
+}
+
+func printParams(params []dao.TaskParam) {
+	fmt.Printf("params: \n")
+	for _, param := range params {
+		var attrs []string
+		if param.Required {
+			attrs = append(attrs, "required")
+		}
+		if param.Default != nil {
+			attrs = append(attrs, fmt.Sprintf("default=%s", *param.Default))
+		}
+		if len(param.Allowed) > 0 {
+			attrs = append(attrs, fmt.Sprintf("allowed=%s", strings.Join(param.Allowed, "|")))
+		}
+		if param.Regex != "" {
+			attrs = append(attrs, fmt.Sprintf("regex=%s", param.Regex))
+		}
+		if param.Secret {
+			attrs = append(attrs, "secret")
+		}
+
+		line := param.Name
+		if len(attrs) > 0 {
+			line += fmt.Sprintf(" [%s]", strings.Join(attrs, ", "))
+		}
+		if param.Desc != "" {
+			line += fmt.Sprintf(": %s", param.Desc)
+		}
+		fmt.Printf("%4s%s\n", " ", line)
 	}
 }
 
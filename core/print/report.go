@@ -250,6 +250,8 @@ func PrintTaskReport(
 				v = FailedPrint.Sprint(t.Status.String())
 			case dao.Unreachable:
 				v = UnreachablePrint.Sprint(t.Status.String())
+			case dao.Interrupted:
+				v = InterruptedPrint.Sprint(t.Status.String())
 			}
 
 			data.Rows[i].Columns = append(data.Rows[i].Columns, v)
@@ -293,6 +295,12 @@ func PrintSummaryReport(
 		dao.Skipped,
 	}
 
+	// Only show interrupted column if the run was interrupted
+	if reportData.Status[dao.Interrupted] > 0 {
+		data.Headers = append(data.Headers, "")
+		taskStatuses = append(taskStatuses, dao.Interrupted)
+	}
+
 	for i := range reportData.Tasks {
 		data.Rows = append(data.Rows, dao.Row{})
 		name := getStatusName(reportData.Tasks[i].Name, reportData.Tasks[i].Status)
@@ -306,16 +314,7 @@ func PrintSummaryReport(
 	// Don't calculate total if only 1 server
 	if len(reportData.Tasks) > 1 {
 		theme.Table.Options.SeparateFooter = core.Ptr(true)
-		if reportData.Status[dao.Failed] == 0 && reportData.Status[dao.Unreachable] == 0 {
-			tot := OkPrint.Sprintf("%s", "Total")
-			data.Footers = append(data.Footers, tot)
-		} else if reportData.Status[dao.Unreachable] > 0 {
-			data.Footers = append(data.Footers, FailedPrint.Sprintf("%s", "Total"))
-		} else if reportData.Status[dao.Ok] == 0 {
-			data.Footers = append(data.Footers, SkippedPrint.Sprintf("%s", "Total"))
-		} else {
-			data.Footers = append(data.Footers, FailedPrint.Sprintf("%s", "Total"))
-		}
+		data.Footers = append(data.Footers, getTotalName(reportData.Status))
 		for _, s := range taskStatuses {
 			val := getTotalStatus(s, reportData.Status)
 			data.Footers = append(data.Footers, val)
@@ -342,7 +341,7 @@ func printRecapHeader(h string, filler string) {
 
 func getStatusName(name string, status map[dao.TaskStatus]int) string {
 	var out string
-	if status[dao.Failed] > 0 || status[dao.Unreachable] > 0 {
+	if status[dao.Failed] > 0 || status[dao.Unreachable] > 0 || status[dao.Interrupted] > 0 {
 		out = FailedPrint.Sprintf("%s\t", name)
 	} else if status[dao.Ok] == 0 && status[dao.Skipped] > 0 {
 		out = SkippedPrint.Sprintf("%s\t", name)
@@ -352,6 +351,15 @@ func getStatusName(name string, status map[dao.TaskStatus]int) string {
 	return out
 }
 
+func getTotalName(status map[dao.TaskStatus]int) string {
+	if status[dao.Failed] > 0 || status[dao.Unreachable] > 0 || status[dao.Interrupted] > 0 {
+		return FailedPrint.Sprintf("%s", "Total")
+	} else if status[dao.Ok] == 0 && status[dao.Skipped] > 0 {
+		return SkippedPrint.Sprintf("%s", "Total")
+	}
+	return OkPrint.Sprintf("%s", "Total")
+}
+
 // func getTotalStatus(s dao.TaskStatus, reportData dao.ReportData) string {
 func getTotalStatus(s dao.TaskStatus, status map[dao.TaskStatus]int) string {
 	var val string
@@ -370,6 +378,8 @@ func getTotalStatus(s dao.TaskStatus, status map[dao.TaskStatus]int) string {
 			val = FailedPrint.Sprintf("%s=%s", s, v)
 		case dao.Unreachable:
 			val = FailedPrint.Sprintf("%s=%s", s, v)
+		case dao.Interrupted:
+			val = InterruptedPrint.Sprintf("%s=%s", s, v)
 		}
 	} else {
 		val = ZeroPrint.Sprintf("%s=%s", s, v)
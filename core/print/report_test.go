@@ -0,0 +1,33 @@
+package print
+
+import (
+	"testing"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/alajmo/sake/core/dao"
+	"github.com/alajmo/sake/core/test"
+)
+
+func TestGetTotalName(t *testing.T) {
+	text.EnableColors()
+
+	ok := OkPrint.Sprintf("%s", "Total")
+	failed := FailedPrint.Sprintf("%s", "Total")
+	skipped := SkippedPrint.Sprintf("%s", "Total")
+
+	test.CheckEqS(t, getTotalName(map[dao.TaskStatus]int{dao.Ok: 2}), ok)
+	test.CheckEqS(t, getTotalName(map[dao.TaskStatus]int{dao.Ok: 1, dao.Interrupted: 1}), failed)
+	test.CheckEqS(t, getTotalName(map[dao.TaskStatus]int{dao.Ok: 1, dao.Unreachable: 1}), failed)
+	test.CheckEqS(t, getTotalName(map[dao.TaskStatus]int{dao.Ok: 1, dao.Failed: 1}), failed)
+	test.CheckEqS(t, getTotalName(map[dao.TaskStatus]int{dao.Failed: 1}), failed)
+	test.CheckEqS(t, getTotalName(map[dao.TaskStatus]int{dao.Skipped: 2}), skipped)
+}
+
+func TestGetStatusName(t *testing.T) {
+	text.EnableColors()
+
+	test.CheckEqS(t, getStatusName("web", map[dao.TaskStatus]int{dao.Ok: 1, dao.Interrupted: 1}), FailedPrint.Sprintf("%s\t", "web"))
+	test.CheckEqS(t, getStatusName("web", map[dao.TaskStatus]int{dao.Skipped: 1}), SkippedPrint.Sprintf("%s\t", "web"))
+	test.CheckEqS(t, getStatusName("web", map[dao.TaskStatus]int{dao.Ok: 1}), OkPrint.Sprintf("%s\t", "web"))
+}
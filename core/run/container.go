@@ -0,0 +1,269 @@
+package run
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/alajmo/sake/core/dao"
+)
+
+// ContainerClient runs commands in a container or pod via the local docker or kubectl binary.
+type ContainerClient struct {
+	Name       string
+	User       string
+	Host       string // container for docker, pod for kubectl
+	Port       uint16
+	Connection string
+	Container  string
+	Namespace  string
+
+	Sessions []LocalSession
+}
+
+// Connect checks that the binary exists and that the container or pod is running.
+func (c *ContainerClient) Connect(_ SSHDialFunc, _ bool, _ string, timeout uint, _ *sync.Mutex) *ErrConnect {
+	bin, err := exec.LookPath(c.Connection)
+	if err != nil {
+		return c.errConnect(err.Error())
+	}
+
+	var args []string
+	var want string
+	switch c.Connection {
+	case dao.ConnectionDocker:
+		args = []string{"inspect", "--type", "container", "--format", "{{.State.Running}}", c.Host}
+		want = "true"
+	case dao.ConnectionKubectl:
+		args = []string{"get", "pod", c.Host, "--output", "jsonpath={.status.phase}"}
+		if c.Namespace != "" {
+			args = append(args, "--namespace", c.Namespace)
+		}
+		want = "Running"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, bin, args...).CombinedOutput()
+	if err != nil {
+		return c.errConnect(strings.TrimSpace(fmt.Sprintf("%v %s", err, out)))
+	}
+
+	if state := strings.TrimSpace(string(out)); state != want {
+		return c.errConnect(fmt.Sprintf("%s is not running", c.Host))
+	}
+
+	return nil
+}
+
+func (c *ContainerClient) errConnect(reason string) *ErrConnect {
+	return &ErrConnect{
+		Name:   c.Name,
+		User:   c.User,
+		Host:   c.Host,
+		Port:   c.Port,
+		Reason: reason,
+	}
+}
+
+// execArgs returns the docker/kubectl exec arguments up to the command to run in the container.
+func (c *ContainerClient) execArgs(tty bool) []string {
+	args := []string{"exec", "--interactive"}
+	if tty {
+		args = append(args, "--tty")
+	}
+
+	switch c.Connection {
+	case dao.ConnectionDocker:
+		if c.User != "" {
+			args = append(args, "--user", c.User)
+		}
+		args = append(args, c.Host)
+	case dao.ConnectionKubectl:
+		if c.Namespace != "" {
+			args = append(args, "--namespace", c.Namespace)
+		}
+		if c.Container != "" {
+			args = append(args, "--container", c.Container)
+		}
+		args = append(args, c.Host, "--")
+	}
+
+	return args
+}
+
+// pidWrapper prints the pid of the command on the first line of stderr before running it,
+// so that signals can be sent to the command in the container.
+const pidWrapper = `echo $$ >&2; exec sh -c "$1"`
+
+// runArgs returns the docker/kubectl arguments to run cmdStr. The command is run by sh in
+// the container, and wrapped in shell if set.
+func (c *ContainerClient) runArgs(env []string, workDir string, shell string, cmdStr string) []string {
+	cmdString := remoteCmdString(env, workDir, shell, cmdStr)
+	return append(c.execArgs(false), "sh", "-c", pidWrapper, "sh", cmdString)
+}
+
+// pidReader reads the pid printed by pidWrapper from the first line of stderr and passes the
+// rest of stderr through. If the first line is not a pid, for instance an error of docker or
+// kubectl, it's passed through as well.
+type pidReader struct {
+	r       *bufio.Reader
+	pid     *atomic.Int64
+	pidRead bool
+	pending []byte // first line, if it's not a pid
+}
+
+func (p *pidReader) Read(b []byte) (int, error) {
+	if !p.pidRead {
+		p.pidRead = true
+		line, err := p.r.ReadString('\n')
+		if pid, perr := strconv.Atoi(strings.TrimSpace(line)); perr == nil && strings.HasSuffix(line, "\n") {
+			p.pid.Store(int64(pid))
+		} else {
+			p.pending = []byte(line)
+		}
+		if err != nil && len(p.pending) == 0 {
+			return 0, err
+		}
+	}
+
+	if len(p.pending) > 0 {
+		n := copy(b, p.pending)
+		p.pending = p.pending[n:]
+		return n, nil
+	}
+
+	return p.r.Read(b)
+}
+
+func (c *ContainerClient) Run(i int, env []string, workDir string, shell string, cmdStr string) error {
+	var err error
+
+	if c.Sessions[i].running.Load() {
+		return fmt.Errorf("command already running")
+	}
+
+	cmd := exec.Command(c.Connection, c.runArgs(env, workDir, shell, cmdStr)...)
+	cmd.Env = os.Environ()
+	c.Sessions[i].cmd = cmd
+
+	c.Sessions[i].stdout, err = cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	c.Sessions[i].pid.Store(0)
+	c.Sessions[i].stderr = &pidReader{r: bufio.NewReader(stderr), pid: &c.Sessions[i].pid}
+
+	c.Sessions[i].stdin, err = cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := c.Sessions[i].cmd.Start(); err != nil {
+		return err
+	}
+
+	c.Sessions[i].running.Store(true)
+	return nil
+}
+
+func (c *ContainerClient) Wait(i int) error {
+	if !c.Sessions[i].running.Load() {
+		return fmt.Errorf("trying to wait on stopped command")
+	}
+	err := c.Sessions[i].cmd.Wait()
+	c.Sessions[i].running.Store(false)
+	return err
+}
+
+// Close kills the command in the container and the docker/kubectl process if it's still running.
+func (c *ContainerClient) Close(i int) error {
+	if !c.Sessions[i].running.Load() {
+		return nil
+	}
+
+	_ = c.signalContainer(i, os.Kill)
+	return c.Sessions[i].cmd.Process.Kill()
+}
+
+func (c *ContainerClient) Stdin(i int) io.WriteCloser {
+	return c.Sessions[i].stdin
+}
+
+func (c *ContainerClient) Write(i int, p []byte) (n int, err error) {
+	return c.Sessions[i].stdin.Write(p)
+}
+
+func (c *ContainerClient) WriteClose(i int) error {
+	return c.Sessions[i].stdin.Close()
+}
+
+func (c *ContainerClient) Stderr(i int) io.Reader {
+	return c.Sessions[i].stderr
+}
+
+func (c *ContainerClient) Stdout(i int) io.Reader {
+	return c.Sessions[i].stdout
+}
+
+func (c *ContainerClient) Prefix() (string, string, string, uint16) {
+	return c.Name, c.Host, c.User, c.Port
+}
+
+// Signal sends sig to the command in the container. docker and kubectl exec don't forward
+// signals, so the signal is sent with kill in the container, and only to the local
+// docker/kubectl process if the pid of the command is not known yet.
+func (c *ContainerClient) Signal(i int, sig os.Signal) error {
+	if !c.Sessions[i].running.Load() {
+		return fmt.Errorf("command is not running")
+	}
+
+	if err := c.signalContainer(i, sig); err == nil {
+		return nil
+	}
+
+	return c.Sessions[i].cmd.Process.Signal(sig)
+}
+
+// Names of the signals that can be sent to commands in containers.
+var containerSignals = map[os.Signal]string{
+	os.Interrupt:    "INT",
+	syscall.SIGTERM: "TERM",
+	os.Kill:         "KILL",
+}
+
+// signalContainer sends sig to the process group of the command in the container, or to the
+// command if it doesn't lead a process group.
+func (c *ContainerClient) signalContainer(i int, sig os.Signal) error {
+	pid := c.Sessions[i].pid.Load()
+	name, ok := containerSignals[sig]
+	if pid == 0 || !ok {
+		return fmt.Errorf("cannot send %v to the command in %s", sig, c.Host)
+	}
+
+	kill := fmt.Sprintf("kill -s %[1]s -- -%[2]d 2>/dev/null || kill -s %[1]s %[2]d", name, pid)
+	return exec.Command(c.Connection, append(c.execArgs(false), "sh", "-c", kill)...).Run()
+}
+
+func (c *ContainerClient) GetName() string {
+	return c.Name
+}
+
+func (c *ContainerClient) Connected() bool {
+	return true
+}
@@ -0,0 +1,78 @@
+package run
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alajmo/sake/core/dao"
+	"github.com/alajmo/sake/core/test"
+)
+
+func TestContainerExecArgs(t *testing.T) {
+	docker := ContainerClient{Host: "web", Connection: dao.ConnectionDocker}
+	test.CheckEqualStringArr(t, docker.execArgs(false), []string{"exec", "--interactive", "web"})
+
+	docker.User = "root"
+	test.CheckEqualStringArr(t, docker.execArgs(true), []string{"exec", "--interactive", "--tty", "--user", "root", "web"})
+
+	kubectl := ContainerClient{Host: "api-0", Connection: dao.ConnectionKubectl}
+	test.CheckEqualStringArr(t, kubectl.execArgs(false), []string{"exec", "--interactive", "api-0", "--"})
+
+	kubectl.Namespace = "prod"
+	kubectl.Container = "app"
+	test.CheckEqualStringArr(t, kubectl.execArgs(false), []string{"exec", "--interactive", "--namespace", "prod", "--container", "app", "api-0", "--"})
+}
+
+func TestContainerRunArgs(t *testing.T) {
+	docker := ContainerClient{Host: "web", Connection: dao.ConnectionDocker}
+	args := docker.runArgs([]string{}, "", "", "echo hi")
+	test.CheckEqualStringArr(t, args[:6], []string{"exec", "--interactive", "web", "sh", "-c", pidWrapper})
+	test.CheckEqS(t, args[6], "sh")
+	test.CheckEqS(t, strings.TrimSpace(args[7]), "echo hi")
+
+	// The config shell is not used in containers unless set on the server, task or command
+	server := dao.Server{Connection: dao.ConnectionDocker}
+	task := dao.Task{}
+	cmd := dao.TaskCmd{}
+	test.CheckEqS(t, getShell(&cmd, &task, &server, "bash"), "")
+
+	server.Shell = "bash"
+	test.CheckEqS(t, getShell(&cmd, &task, &server, "zsh"), "bash -c")
+	args = docker.runArgs([]string{}, "/app", getShell(&cmd, &task, &server, ""), "echo hi")
+	if !strings.HasPrefix(args[7], "cd /app && ") || !strings.HasSuffix(args[7], "bash -c 'echo hi'") {
+		t.Fatalf("Wanted: command run by bash in /app, Found: %q", args[7])
+	}
+
+	cmd.Local = true
+	server.Shell = ""
+	test.CheckEqS(t, getShell(&cmd, &task, &server, "zsh"), "zsh -c")
+
+	ssh := dao.Server{Connection: dao.ConnectionSSH}
+	test.CheckEqS(t, getShell(&dao.TaskCmd{}, &task, &ssh, "bash"), "bash -c")
+}
+
+func TestContainerPidReader(t *testing.T) {
+	var pid atomic.Int64
+	r := &pidReader{r: bufio.NewReader(strings.NewReader("42\nerror: not found\n")), pid: &pid}
+
+	out, err := io.ReadAll(r)
+	test.CheckErr(t, err)
+	test.CheckEqS(t, string(out), "error: not found\n")
+	test.CheckEqN(t, int(pid.Load()), 42)
+
+	// Errors printed before the pid, for instance when sh is missing, are passed through
+	pid.Store(0)
+	r = &pidReader{r: bufio.NewReader(strings.NewReader("sh: not found\ncontainer not running")), pid: &pid}
+	out, err = io.ReadAll(r)
+	test.CheckErr(t, err)
+	test.CheckEqS(t, string(out), "sh: not found\ncontainer not running")
+	test.CheckEqN(t, int(pid.Load()), 0)
+
+	r = &pidReader{r: bufio.NewReader(strings.NewReader("Error: No such container: web")), pid: &pid}
+	out, err = io.ReadAll(r)
+	test.CheckErr(t, err)
+	test.CheckEqS(t, string(out), "Error: No such container: web")
+}
@@ -2,16 +2,23 @@ package run
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"text/template"
 
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
 
 	"github.com/jedib0t/go-pretty/v6/text"
 
@@ -27,6 +34,10 @@ type Run struct {
 	UnreachableServers []dao.Server
 	Task               *dao.Task
 	Config             dao.Config
+
+	interrupted atomic.Bool
+	cleanupMu   sync.Mutex // guards CleanupClients, which is also called by the interrupt handler
+	stdin       []byte     // sent to stdin of each command, nil if no input
 }
 
 type TaskContext struct {
@@ -35,7 +46,11 @@ type TaskContext struct {
 	client Client
 	dryRun bool
 	tty    bool
-	print  string
+	pty    bool
+	stdin  []byte
+
+	localEnvClear bool
+	print         string
 
 	desc     string
 	name     string
@@ -68,6 +83,19 @@ func (run *Run) RunTask(
 	if err != nil {
 		return err
 	}
+
+	run.stdin, err = readStdinInput(runFlags.Stdin)
+	if err != nil {
+		return err
+	}
+
+	for i := range run.Servers {
+		run.Servers[i].Envs, err = dao.ResolveSecretEnvs(run.Servers[i].Envs)
+		if err != nil {
+			return err
+		}
+		dao.RegisterSecretEnvs(run.Servers[i].Envs, run.Servers[i].SecretEnvs)
+	}
 	run.CheckTaskNoColor()
 
 	errConnects, err := ParseServers(run.Config.SSHConfigFile, &run.Servers, runFlags, run.Task.Spec.Order)
@@ -175,6 +203,9 @@ func (run *Run) RunTask(
 		return nil
 	}
 
+	stopInterruptHandler := run.handleInterrupt()
+	defer stopInterruptHandler()
+
 	switch task.Spec.Output {
 	case "table", "table-1", "table-2", "table-3", "table-4", "html", "markdown", "json", "csv", "none":
 		spinner := core.GetSpinner()
@@ -211,6 +242,10 @@ func (run *Run) RunTask(
 			return err
 		}
 
+		if run.interrupted.Load() {
+			return &core.ExecError{Err: errInterrupted, ExitCode: 130}
+		}
+
 		if derr != nil {
 			return derr
 		}
@@ -230,6 +265,10 @@ func (run *Run) RunTask(
 			return err
 		}
 
+		if run.interrupted.Load() {
+			return &core.ExecError{Err: errInterrupted, ExitCode: 130}
+		}
+
 		if derr != nil {
 			return derr
 		}
@@ -354,6 +393,36 @@ func (run *Run) SetClients(
 		clientCh <- remote
 	}
 
+	createContainerClient := func(strategy string, numTasks int, server dao.Server, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		container := &ContainerClient{
+			Name:       server.Name,
+			User:       server.User,
+			Host:       server.Host,
+			Port:       server.Port,
+			Connection: server.Connection,
+			Container:  server.Container,
+			Namespace:  server.Namespace,
+		}
+
+		switch strategy {
+		case "free":
+			for i := 0; i < numTasks; i++ {
+				container.Sessions = append(container.Sessions, LocalSession{})
+			}
+		default:
+			container.Sessions = append(container.Sessions, LocalSession{})
+		}
+
+		if err := container.Connect(nil, false, "", run.Config.DefaultTimeout, nil); err != nil {
+			errCh <- *err
+			return
+		}
+
+		clientCh <- container
+	}
+
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
@@ -376,6 +445,10 @@ func (run *Run) SetClients(
 	}
 
 	for _, server := range run.Servers {
+		if server.Connection != dao.ConnectionSSH {
+			continue
+		}
+
 		err := populateSigners(server, &signers)
 		if err != nil {
 			return []ErrConnect{}, err
@@ -386,7 +459,14 @@ func (run *Run) SetClients(
 	for _, server := range run.Servers {
 		wg.Add(1)
 		go createLocalClient(task.Spec.Strategy, len(task.Tasks), server, &wg)
-		if !server.Local {
+		if server.Local {
+			continue
+		}
+
+		if server.Connection != dao.ConnectionSSH {
+			wg.Add(1)
+			go createContainerClient(task.Spec.Strategy, len(task.Tasks), server, &wg)
+		} else {
 			wg.Add(1)
 			authMethods := getAuthMethod(server, &signers)
 			go createRemoteClient(task.Spec.Strategy, len(task.Tasks), authMethods, server, &wg, &mu)
@@ -401,7 +481,7 @@ func (run *Run) SetClients(
 	remoteClients := make(map[string]Client, numChannels/2)
 	for client := range clientCh {
 		switch client.(type) {
-		case *SSHClient:
+		case *SSHClient, *ContainerClient:
 			remoteClients[client.GetName()] = client
 		case *LocalhostClient:
 			localCLients[client.GetName()] = client
@@ -420,14 +500,31 @@ func (run *Run) SetClients(
 }
 
 func (run *Run) CleanupClients() {
+	run.cleanupMu.Lock()
+	defer run.cleanupMu.Unlock()
+
 	clients := run.RemoteClients
 
 	// Close remote connections
 	for _, c := range clients {
-		if remote, ok := c.(*SSHClient); ok {
-			for i := range c.(*SSHClient).Sessions {
+		switch remote := c.(type) {
+		case *SSHClient:
+			for i := range remote.Sessions {
 				_ = remote.Close(i)
 			}
+		case *ContainerClient:
+			for i := range remote.Sessions {
+				_ = remote.Close(i)
+			}
+		}
+	}
+
+	// Kill local commands that are still running
+	for _, c := range run.LocalClients {
+		if local, ok := c.(*LocalhostClient); ok {
+			for i := range local.Sessions {
+				_ = local.Close(i)
+			}
 		}
 	}
 }
@@ -470,6 +567,11 @@ func ParseServers(
 
 	var errConnects []ErrConnect
 	for i := range *servers {
+		// Containers and pods are not resolved via ssh config
+		if (*servers)[i].Connection != dao.ConnectionSSH {
+			continue
+		}
+
 		serv := cfg[(*servers)[i].Host]
 		// Bastion resolve, for instance, host: server-1 has an entry in ssh config
 		// that has ProxyJump, ProxyJump alias or if in sake it has a bastion: server-1
@@ -790,6 +892,11 @@ func (run *Run) ParseTask(
 		run.Task.TTY = runFlags.TTY
 	}
 
+	// If pty flag is set to true, then update task
+	if setRunFlags.PTY {
+		run.Task.PTY = runFlags.PTY
+	}
+
 	// Confirm
 	if setRunFlags.Confirm {
 		run.Task.Spec.Confirm = runFlags.Confirm
@@ -812,11 +919,22 @@ func (run *Run) ParseTask(
 			run.Task.Tasks[j].Local = runFlags.Local
 		}
 
-		envs, err := dao.ParseTaskEnv(run.Task.Tasks[j].Envs, userArgs, run.Task.Envs, configEnv)
+		// If pty flag is set to true, then cmd will run in a remote pseudo-terminal
+		if setRunFlags.PTY {
+			run.Task.Tasks[j].PTY = runFlags.PTY
+		}
+
+		secretEnvs := append(slices.Clone(run.Task.SecretEnvs), run.Config.SecretEnvs...)
+		envs, err := dao.ParseTaskEnv(run.Task.Tasks[j].Envs, userArgs, run.Task.Envs, configEnv, secretEnvs)
 		if err != nil {
 			return err
 		}
 		run.Task.Tasks[j].Envs = envs
+
+		// Commands in a terminal read input from the terminal, so --stdin would be silently ignored
+		if runFlags.Stdin != "" && (run.Task.Tasks[j].PTY || run.Task.Tasks[j].TTY) {
+			return &core.StdinTerminalCmd{Task: run.Task.ID, Cmd: run.Task.Tasks[j].Name}
+		}
 	}
 
 	run.ParseTaskTarget(runFlags, setRunFlags)
@@ -846,6 +964,9 @@ func (run *Run) ParseTaskTarget(
 	if setRunFlags.Regex {
 		run.Task.Target.Regex = runFlags.Regex
 	}
+	if setRunFlags.Where {
+		run.Task.Target.Where = runFlags.Where
+	}
 	if setRunFlags.Invert {
 		run.Task.Target.Invert = runFlags.Invert
 	}
@@ -881,6 +1002,18 @@ func (run *Run) setKnownHostsFile(knownHostsFileFlag string) error {
 	return nil
 }
 
+// getShell returns the first shell set on the command, task, server or config. The config
+// shell is not used for commands in docker and kubectl servers, since many images only
+// ship sh, which is used to run the command when no shell is set.
+func getShell(cmd *dao.TaskCmd, task *dao.Task, server *dao.Server, configShell string) string {
+	if !cmd.Local && !server.Local && server.Connection != "" && server.Connection != dao.ConnectionSSH {
+		configShell = ""
+	}
+
+	shell := dao.SelectFirstNonEmpty(cmd.Shell, task.Shell, server.Shell, configShell)
+	return core.FormatShell(shell)
+}
+
 func getWorkDir(
 	cmdLocal bool,
 	serverLocal bool,
@@ -970,6 +1103,74 @@ func getWorkDir(
 	return ""
 }
 
+// CmdData is the data a command is rendered with when cmd_template is set.
+type CmdData struct {
+	Vars     map[string]any
+	Server   dao.Server
+	Register map[string]string
+}
+
+// cmdRun is a single run of a command, commands that loop over var or register items
+// are run once per item.
+type cmdRun struct {
+	name string // loop item name, see dao.LoopItemName
+	cmd  string
+	envs []string
+}
+
+// expandCmd returns the runs of a command on a server.
+// Task vars take precedence over target, server and config vars.
+func (run *Run) expandCmd(r ServerTask, register map[string]string) ([]cmdRun, error) {
+	vars := dao.MergeVars(r.Cmd.Vars, r.Task.Target.Vars, r.Server.Vars, run.Config.Vars)
+
+	if r.Cmd.Loop == nil {
+		cmd, err := renderCmd(r, vars, register)
+		return []cmdRun{{cmd: cmd}}, err
+	}
+
+	items, err := r.Cmd.Loop.Resolve(vars, register)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []cmdRun{}
+	for i, item := range items {
+		cmd, err := renderCmd(r, dao.MergeVars(dao.LoopVars(item, i), vars), register)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, cmdRun{name: dao.LoopItemName(r.Cmd.Name, item), cmd: cmd, envs: dao.LoopEnvs(item, i)})
+	}
+
+	return runs, nil
+}
+
+// renderCmd renders the command as a Go template if cmd_template is set.
+func renderCmd(r ServerTask, vars map[string]any, register map[string]string) (string, error) {
+	if !r.Cmd.CmdTemplate {
+		return r.Cmd.Cmd, nil
+	}
+
+	data := CmdData{
+		Vars:     vars,
+		Server:   *r.Server,
+		Register: maps.Clone(register),
+	}
+
+	tmpl, err := template.New("cmd.tmpl").Option("missingkey=error").Parse(r.Cmd.Cmd)
+	if err != nil {
+		return "", &core.TemplateParseError{Msg: err.Error()}
+	}
+
+	buf := &bytes.Buffer{}
+	err = tmpl.Execute(buf, data)
+	if err != nil {
+		return "", &core.TemplateParseError{Msg: err.Error()}
+	}
+
+	return buf.String(), nil
+}
+
 func populateSigners(server dao.Server, signers *Signers) error {
 	// If no identity or password provided, return
 	if server.IdentityFile == nil && server.Password == nil {
@@ -1067,6 +1268,76 @@ func CalcForks(batch int, forks uint32) int {
 	return int(forks)
 }
 
+// readStdinInput returns the input that is sent to stdin of each command, either the
+// content of file, or stdin if file is `-`.
+func readStdinInput(file string) ([]byte, error) {
+	switch file {
+	case "":
+		return nil, nil
+	case "-":
+		return io.ReadAll(os.Stdin)
+	default:
+		return os.ReadFile(file)
+	}
+}
+
+// writeStdinInput writes input to stdin of the command and closes it, so the command
+// receives EOF after the input.
+func writeStdinInput(i int, client Client, input []byte) {
+	_, _ = client.Write(i, input)
+	_ = client.WriteClose(i)
+}
+
+// ResolveTaskParams validates user arguments against the params of the task and returns them with
+// defaults and prompted values added. It's called before the inventory is parsed, so inventory
+// commands receive the resolved values. Arguments for params of the other tasks in taskIDs are
+// accepted as well.
+func ResolveTaskParams(config dao.Config, task dao.Task, userArgs []string, taskIDs []string) ([]string, error) {
+	var prompt dao.ParamPrompt
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		prompt = promptParam
+	}
+
+	knownEnvs := slices.Clone(config.Envs)
+	for _, server := range config.Servers {
+		knownEnvs = append(knownEnvs, server.Envs...)
+	}
+	for _, id := range taskIDs {
+		other, err := config.GetTask(id)
+		if err != nil {
+			return []string{}, err
+		}
+		for _, param := range other.Params {
+			knownEnvs = append(knownEnvs, param.Name+"=")
+		}
+	}
+
+	return task.ResolveParams(userArgs, knownEnvs, prompt)
+}
+
+// promptParam reads the value of a required param from the terminal, without echo for secrets.
+func promptParam(param dao.TaskParam) (string, error) {
+	if param.Desc != "" {
+		fmt.Fprintf(os.Stderr, "%s (%s): ", param.Name, param.Desc)
+	} else {
+		fmt.Fprintf(os.Stderr, "%s: ", param.Name)
+	}
+
+	if param.Secret {
+		value, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return string(value), err
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	value, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(value, "\r\n"), nil
+}
+
 func confirmExecute(taskName string) bool {
 	var mu sync.Mutex
 
@@ -1,8 +1,14 @@
 package run
 
 import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/alajmo/sake/core"
+	"github.com/alajmo/sake/core/dao"
 	"github.com/alajmo/sake/core/test"
 )
 
@@ -32,3 +38,120 @@ func TestWorkDir(t *testing.T) {
 	test.CheckEqS(t, getWorkDir(false, true, "", "server", "cmd-root", "server-root"), "server-root/server")
 	test.CheckEqS(t, getWorkDir(true, true, "", "server", "cmd-root", "server-root"), "server-root/server")
 }
+
+func TestExpandCmd(t *testing.T) {
+	run := Run{Config: dao.Config{Vars: map[string]any{"env": "prod", "services": []any{"nginx"}}}}
+	r := ServerTask{
+		Server: &dao.Server{Host: "192.168.0.1", Vars: map[string]any{"services": []any{"api", "worker"}}},
+		Task:   &dao.Task{Target: dao.Target{Vars: map[string]any{"env": "staging"}}},
+		Cmd: &dao.TaskCmd{
+			Cmd:         `{{ range .Vars.services }}{{ . }}@{{ $.Server.Host }}/{{ $.Vars.env }} {{ end }}{{ .Register.out }}`,
+			CmdTemplate: true,
+		},
+	}
+
+	runs, err := run.expandCmd(r, map[string]string{"out": "done"})
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	test.CheckEqS(t, runs[0].cmd, "api@192.168.0.1/staging worker@192.168.0.1/staging done")
+
+	r.Cmd.Cmd = "{{ .Vars.missing }}"
+	if _, err := run.expandCmd(r, nil); err == nil {
+		t.Fatalf("expected error for missing var")
+	}
+
+	// Loop over server var
+	r.Cmd.Cmd = "restart {{ .Vars.item }} {{ .Vars.item_index }}"
+	r.Cmd.Loop = &dao.TaskLoop{Var: "services"}
+	runs, err = run.expandCmd(r, nil)
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	test.CheckEqN(t, len(runs), 2)
+	test.CheckEqS(t, runs[1].cmd, "restart worker 1")
+	test.CheckEqS(t, runs[1].name, "worker")
+	test.CheckEqualStringArr(t, runs[1].envs, []string{"item=worker", "item_index=1"})
+
+	// Loop over registered stdout
+	r.Cmd.Loop = &dao.TaskLoop{Register: "units"}
+	runs, err = run.expandCmd(r, map[string]string{"units_stdout": "a.service\n\nb.service\n"})
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	test.CheckEqN(t, len(runs), 2)
+	test.CheckEqS(t, runs[0].cmd, "restart a.service 0")
+
+	r.Cmd.CmdTemplate = false
+	r.Cmd.Loop = nil
+	runs, _ = run.expandCmd(r, nil)
+	test.CheckEqS(t, runs[0].cmd, "restart {{ .Vars.item }} {{ .Vars.item_index }}")
+}
+
+func TestStdinInput(t *testing.T) {
+	input, err := readStdinInput("")
+	test.CheckErr(t, err)
+	if input != nil {
+		t.Fatalf("Wanted: no input without --stdin, Found: %q", input)
+	}
+
+	file := filepath.Join(t.TempDir(), "input.sql")
+	err = os.WriteFile(file, []byte("select 1;\n"), 0o600)
+	test.CheckErr(t, err)
+
+	input, err = readStdinInput(file)
+	test.CheckErr(t, err)
+	test.CheckEqS(t, string(input), "select 1;\n")
+
+	_, err = readStdinInput(filepath.Join(t.TempDir(), "missing"))
+	test.IsError(t, err)
+
+	// The command receives the input followed by EOF
+	client := LocalhostClient{Sessions: []LocalSession{{}}}
+	err = client.Run(0, []string{}, "", "sh -c", "cat")
+	test.CheckErr(t, err)
+	go writeStdinInput(0, &client, input)
+	out, err := io.ReadAll(client.Stdout(0))
+	test.CheckErr(t, err)
+	test.CheckErr(t, client.Wait(0))
+	test.CheckEqS(t, string(out), "select 1;\n")
+
+	// Commands with pty set in the config can't receive --stdin
+	run := Run{Task: &dao.Task{ID: "psql", Tasks: []dao.TaskCmd{{Name: "shell", PTY: true}}}}
+	err = run.ParseTask([]string{}, []string{}, &core.RunFlags{Stdin: file}, &core.SetRunFlags{})
+	var stdinErr *core.StdinTerminalCmd
+	if !errors.As(err, &stdinErr) {
+		t.Fatalf("Wanted: StdinTerminalCmd error, Found: %v", err)
+	}
+}
+
+func TestResolveTaskParams(t *testing.T) {
+	def := "eu"
+	deploy := dao.Task{ID: "deploy", Params: []dao.TaskParam{{Name: "region", Default: &def}}}
+	migrate := dao.Task{ID: "migrate", Params: []dao.TaskParam{{Name: "schema", Default: &def}}}
+	config := dao.Config{
+		Tasks: []dao.Task{deploy, migrate},
+		Servers: []dao.Server{
+			{Name: "web", Inventory: "echo web-$region.example.com", Envs: []string{"ZONE=a"}},
+		},
+	}
+
+	// Args for params of other tasks and server envs are accepted
+	args, err := ResolveTaskParams(config, deploy, []string{"schema=v2", "ZONE=b"}, []string{"deploy", "migrate"})
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+
+	_, err = ResolveTaskParams(config, deploy, []string{"schema=v2"}, []string{"deploy"})
+	if err == nil {
+		t.Fatalf("Wanted: error for unknown param")
+	}
+
+	// Inventory commands receive param defaults
+	err = config.ParseInventory(args)
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	test.CheckEqN(t, len(config.Servers), 1)
+	test.CheckEqS(t, config.Servers[0].Host, "web-eu.example.com")
+}
@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // Client is a wrapper over the SSH connection/Sessions.
@@ -21,11 +22,13 @@ type LocalhostClient struct {
 }
 
 type LocalSession struct {
-	stdin   io.WriteCloser
-	cmd     *exec.Cmd
-	stdout  io.Reader
-	stderr  io.Reader
-	running bool
+	stdin    io.WriteCloser
+	cmd      *exec.Cmd
+	stdout   io.Reader
+	stderr   io.Reader
+	running  atomic.Bool
+	pid      atomic.Int64 // pid of the command in the container, containers only
+	envClear bool
 }
 
 func (c *LocalhostClient) Connect(dialer SSHDialFunc, _ bool, _ string, _ uint, mu *sync.Mutex) *ErrConnect {
@@ -35,11 +38,14 @@ func (c *LocalhostClient) Connect(dialer SSHDialFunc, _ bool, _ string, _ uint,
 func (c *LocalhostClient) Run(i int, env []string, workDir string, shell string, cmdStr string) error {
 	var err error
 
-	if c.Sessions[i].running {
+	if c.Sessions[i].running.Load() {
 		return fmt.Errorf("command already running")
 	}
 
 	userEnv := os.Environ()
+	if c.Sessions[i].envClear {
+		userEnv = minimalEnv()
+	}
 
 	if shell == "" {
 		shell = dao.DEFAULT_SHELL
@@ -58,6 +64,8 @@ func (c *LocalhostClient) Run(i int, env []string, workDir string, shell string,
 
 	cmd := exec.Command(shellProgram, shellArgs...)
 	cmd.Env = append(userEnv, env...)
+	// Run in a separate process group so that signals also reach processes started by the command
+	setProcessGroup(cmd)
 	c.Sessions[i].cmd = cmd
 
 	c.Sessions[i].stdout, err = cmd.StdoutPipe()
@@ -79,21 +87,35 @@ func (c *LocalhostClient) Run(i int, env []string, workDir string, shell string,
 		return err
 	}
 
-	c.Sessions[i].running = true
+	c.Sessions[i].running.Store(true)
 	return nil
 }
 
 func (c *LocalhostClient) Wait(i int) error {
-	if !c.Sessions[i].running {
+	if !c.Sessions[i].running.Load() {
 		return fmt.Errorf("trying to wait on stopped command")
 	}
 	err := c.Sessions[i].cmd.Wait()
-	c.Sessions[i].running = false
+	c.Sessions[i].running.Store(false)
+	if releaseForeground(c.Sessions[i].cmd) {
+		return &interruptedErr{err: err}
+	}
 	return err
 }
 
+// Close kills the command and all processes it started, if it's still running.
 func (c *LocalhostClient) Close(i int) error {
-	return nil
+	if !c.Sessions[i].running.Load() {
+		return nil
+	}
+
+	return signalProcessGroup(c.Sessions[i].cmd, os.Kill)
+}
+
+// SetEnvClear sets whether the next command in session i starts from a minimal
+// environment instead of the current process environment.
+func (c *LocalhostClient) SetEnvClear(i int, clear bool) {
+	c.Sessions[i].envClear = clear
 }
 
 func (c *LocalhostClient) Stdin(i int) io.WriteCloser {
@@ -121,7 +143,11 @@ func (c *LocalhostClient) Prefix() (string, string, string, uint16) {
 }
 
 func (c *LocalhostClient) Signal(i int, sig os.Signal) error {
-	return c.Sessions[i].cmd.Process.Signal(sig)
+	if !c.Sessions[i].running.Load() {
+		return fmt.Errorf("command is not running")
+	}
+
+	return signalProcessGroup(c.Sessions[i].cmd, sig)
 }
 
 func (c *LocalhostClient) GetName() string {
@@ -131,3 +157,16 @@ func (c *LocalhostClient) GetName() string {
 func (c *LocalhostClient) Connected() bool {
 	return true
 }
+
+// minimalEnv returns the subset of the current process environment that is
+// required to run a shell.
+func minimalEnv() []string {
+	var env []string
+	for _, key := range minimalEnvKeys {
+		if value, found := os.LookupEnv(key); found {
+			env = append(env, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+
+	return env
+}
@@ -0,0 +1,27 @@
+package run
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMinimalEnv(t *testing.T) {
+	t.Setenv("HOME", "/home/test")
+	t.Setenv("SAKE_TEST_SECRET", "secret")
+
+	env := minimalEnv()
+
+	var found bool
+	for _, e := range env {
+		if strings.HasPrefix(e, "SAKE_TEST_SECRET=") {
+			t.Fatalf("minimal env should not contain %q", e)
+		}
+		if e == "HOME=/home/test" {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatalf("Wanted: %q in %q", "HOME=/home/test", env)
+	}
+}
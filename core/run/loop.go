@@ -0,0 +1,111 @@
+package run
+
+import (
+	"errors"
+	"os/exec"
+	"slices"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/alajmo/sake/core/dao"
+)
+
+// loopReport returns the report of a loop iteration that ran with the error err.
+func (run *Run) loopReport(r ServerTask, c cmdRun, start time.Time, output string, err error) dao.LoopReport {
+	report := dao.Report{Duration: time.Since(start), Status: dao.Ok}
+
+	switch err := err.(type) {
+	case *ssh.ExitError:
+		report.ReturnCode = err.ExitStatus()
+	case *exec.ExitError:
+		report.ReturnCode = err.ExitCode()
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errInterrupted) || run.interrupted.Load():
+			report.Status = dao.Interrupted
+		case r.Task.Spec.IgnoreErrors || r.Cmd.IgnoreErrors:
+			report.Status = dao.Ignored
+		default:
+			report.Status = dao.Failed
+		}
+	}
+
+	return dao.LoopReport{Name: c.name, Output: output, Report: report}
+}
+
+// splitLoopColumns replaces the column of each var and register loop with a column per item,
+// once the task has run. Since the items are resolved per server, servers that didn't run an
+// item are left empty in its column. data is nil for text output.
+func splitLoopColumns(tasks []dao.TaskCmd, data *dao.TableOutput, reportData *dao.ReportData) {
+	for j := len(tasks) - 1; j >= 0; j-- {
+		if tasks[j].Loop == nil {
+			continue
+		}
+
+		// Items repeated on a server are given a column per repetition
+		names := []string{}
+		keys := []string{}
+		rowKeys := make([]map[string]int, len(reportData.Tasks))
+		for i := range reportData.Tasks {
+			rowKeys[i] = make(map[string]int)
+			seen := make(map[string]int)
+			for k, l := range reportData.Tasks[i].Rows[j].Loop {
+				key := l.Name + "\x00" + strconv.Itoa(seen[l.Name])
+				seen[l.Name] += 1
+				rowKeys[i][key] = k
+
+				if !slices.Contains(keys, key) {
+					keys = append(keys, key)
+					names = append(names, l.Name)
+				}
+			}
+		}
+
+		if len(keys) == 0 {
+			continue
+		}
+
+		// First column is the server
+		c := j + 1
+		reportData.Headers = splice(reportData.Headers, c, names)
+		if data != nil {
+			data.Headers = splice(data.Headers, c, names)
+		}
+
+		for i := range reportData.Tasks {
+			cell := reportData.Tasks[i].Rows[j]
+			reports := make([]dao.Report, len(keys))
+			columns := make([]string, len(keys))
+			for n, key := range keys {
+				if k, ok := rowKeys[i][key]; ok {
+					reports[n] = cell.Loop[k].Report
+					columns[n] = cell.Loop[k].Output
+				} else if len(cell.Loop) == 0 {
+					// No item ran, for instance the server is unreachable or an earlier task failed
+					reports[n] = cell
+					reports[n].Loop = nil
+				}
+			}
+
+			if data != nil && i < len(data.Rows) {
+				if len(cell.Loop) == 0 {
+					columns[0] = data.Rows[i].Columns[c]
+				}
+				data.Rows[i].Columns = splice(data.Rows[i].Columns, c, columns)
+			}
+			reportData.Tasks[i].Rows = splice(reportData.Tasks[i].Rows, j, reports)
+		}
+	}
+}
+
+// splice returns s with the element at index i replaced by values.
+func splice[T any](s []T, i int, values []T) []T {
+	out := make([]T, 0, len(s)+len(values)-1)
+	out = append(out, s[:i]...)
+	out = append(out, values...)
+	return append(out, s[i+1:]...)
+}
@@ -0,0 +1,67 @@
+package run
+
+import (
+	"testing"
+
+	"github.com/alajmo/sake/core/dao"
+	"github.com/alajmo/sake/core/test"
+)
+
+func TestSplitLoopColumns(t *testing.T) {
+	tasks := []dao.TaskCmd{
+		{Name: "ping"},
+		{Name: "restart", Loop: &dao.TaskLoop{Var: "services"}},
+		{Name: "done"},
+	}
+
+	iteration := func(name string, output string, status dao.TaskStatus) dao.LoopReport {
+		return dao.LoopReport{Name: name, Output: output, Report: dao.Report{Status: status}}
+	}
+
+	data := dao.TableOutput{
+		Headers: []string{"host", "ping", "restart", "done"},
+		Rows: []dao.Row{
+			{Columns: []string{"web", "pong", "api\nworker", "ok"}},
+			{Columns: []string{"db", "pong", "api\nworker\nworker", "ok"}},
+			{Columns: []string{"cache", "pong", "not found", ""}},
+		},
+	}
+	reportData := dao.ReportData{
+		Headers: []string{"host", "ping", "restart", "done"},
+		Tasks: []dao.ReportRow{
+			{Name: "web", Rows: []dao.Report{{Status: dao.Ok}, {Status: dao.Ok, Loop: []dao.LoopReport{
+				iteration("restart (api)", "api", dao.Ok),
+				iteration("restart (worker)", "worker", dao.Ok),
+			}}, {Status: dao.Ok}}},
+			{Name: "db", Rows: []dao.Report{{Status: dao.Ok}, {Status: dao.Failed, Loop: []dao.LoopReport{
+				iteration("restart (worker)", "worker", dao.Ok),
+				iteration("restart (worker)", "worker", dao.Failed),
+			}}, {}}},
+			{Name: "cache", Rows: []dao.Report{{Status: dao.Ok}, {Status: dao.Failed}, {}}},
+			{Name: "unreachable", Rows: []dao.Report{{Status: dao.Unreachable}, {Status: dao.Unreachable}, {Status: dao.Unreachable}}},
+		},
+	}
+
+	splitLoopColumns(tasks, &data, &reportData)
+
+	// A column per item, repeated items on a server are given a column per repetition
+	headers := []string{"host", "ping", "restart (api)", "restart (worker)", "restart (worker)", "done"}
+	test.CheckEqualStringArr(t, data.Headers, headers)
+	test.CheckEqualStringArr(t, reportData.Headers, headers)
+
+	test.CheckEqualStringArr(t, data.Rows[0].Columns, []string{"web", "pong", "api", "worker", "", "ok"})
+	test.CheckEqualStringArr(t, data.Rows[1].Columns, []string{"db", "pong", "", "worker", "worker", "ok"})
+	test.CheckEqualStringArr(t, data.Rows[2].Columns, []string{"cache", "pong", "not found", "", "", ""})
+
+	statuses := func(reports []dao.Report) []string {
+		s := []string{}
+		for _, r := range reports {
+			s = append(s, r.Status.String())
+		}
+		return s
+	}
+	test.CheckEqualStringArr(t, statuses(reportData.Tasks[0].Rows), []string{"ok", "ok", "ok", "skipped", "ok"})
+	test.CheckEqualStringArr(t, statuses(reportData.Tasks[1].Rows), []string{"ok", "skipped", "ok", "failed", "skipped"})
+	test.CheckEqualStringArr(t, statuses(reportData.Tasks[2].Rows), []string{"ok", "failed", "failed", "failed", "skipped"})
+	test.CheckEqualStringArr(t, statuses(reportData.Tasks[3].Rows), []string{"unreachable", "unreachable", "unreachable", "unreachable", "unreachable"})
+}
@@ -0,0 +1,101 @@
+package run
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"golang.org/x/term"
+
+	"github.com/alajmo/sake/core"
+)
+
+// Only one remote pseudo-terminal can own the local terminal at a time,
+// so sessions on multiple hosts are run one after another.
+var ptyMu sync.Mutex
+
+// runPTYCmd runs a command in a pseudo-terminal on the remote host and attaches the
+// local terminal to it. The local terminal is put in raw mode for the duration of the
+// command and window size changes are forwarded to the remote host.
+func runPTYCmd(i int, t TaskContext, client *SSHClient) (string, string, string, error) {
+	ptyMu.Lock()
+	defer ptyMu.Unlock()
+
+	buf := new(bytes.Buffer)
+	bufOut := new(bytes.Buffer)
+	bufErr := new(bytes.Buffer)
+
+	fd := int(os.Stdin.Fd())
+	isTerminal := term.IsTerminal(fd)
+
+	width, height := 80, 24
+	if isTerminal {
+		if w, h, err := term.GetSize(fd); err == nil {
+			width, height = w, h
+		}
+	}
+
+	termType, found := os.LookupEnv("TERM")
+	if !found || termType == "" {
+		termType = "xterm"
+	}
+
+	err := client.RunPTY(i, t.env, t.workDir, t.shell, t.cmd, termType, height, width)
+	if err != nil {
+		return buf.String(), bufOut.String(), bufErr.String(), err
+	}
+
+	if isTerminal {
+		oldState, err := term.MakeRaw(fd)
+		if err != nil {
+			_ = client.Close(i)
+			return buf.String(), bufOut.String(), bufErr.String(), err
+		}
+		defer func() { _ = term.Restore(fd, oldState) }()
+
+		stopWatch := watchWindowSize(fd, func(width int, height int) {
+			_ = client.WindowChange(i, height, width)
+		})
+		defer stopWatch()
+	}
+
+	// Forward local input until the command exits
+	stopInput, err := forwardInput(os.Stdin, client.Stdin(i))
+	if err != nil {
+		_ = client.Close(i)
+		return buf.String(), bufOut.String(), bufErr.String(), err
+	}
+	defer stopInput()
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mw := io.MultiWriter(os.Stdout, buf, bufOut)
+		_, err := io.Copy(mw, core.NewMaskReader(client.Stdout(i)))
+		if err != nil && err != io.EOF {
+			fmt.Fprintf(os.Stderr, "%v", err)
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mw := io.MultiWriter(os.Stderr, buf, bufErr)
+		_, err := io.Copy(mw, core.NewMaskReader(client.Stderr(i)))
+		if err != nil && err != io.EOF {
+			fmt.Fprintf(os.Stderr, "%v", err)
+		}
+	}()
+
+	wg.Wait()
+
+	if err := client.Wait(i); err != nil {
+		return buf.String(), bufOut.String(), bufErr.String(), err
+	}
+
+	return buf.String(), bufOut.String(), bufErr.String(), nil
+}
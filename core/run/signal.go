@@ -0,0 +1,103 @@
+package run
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Time to wait for running commands to exit after forwarding a signal, before escalating.
+const interruptGracePeriod = 3 * time.Second
+
+var errInterrupted = errors.New("interrupted")
+
+// interruptedErr is returned by commands that were interrupted by Ctrl-C before sake was.
+type interruptedErr struct {
+	err error
+}
+
+func (e *interruptedErr) Error() string {
+	return e.err.Error()
+}
+
+func (e *interruptedErr) Unwrap() []error {
+	return []error{e.err, errInterrupted}
+}
+
+// handleInterrupt forwards Ctrl-C to all running sessions. Sessions first receive SIGINT,
+// then SIGTERM if they're still running after the grace period, and lastly the connections
+// are closed. Pressing Ctrl-C again skips the remaining grace period, and once the
+// connections are closed, the default signal behavior is restored.
+// The returned function stops the handler.
+func (run *Run) handleInterrupt() func() {
+	sigCh := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigCh, os.Interrupt)
+
+	go func() {
+		defer signal.Stop(sigCh)
+
+		select {
+		case <-sigCh:
+		case <-done:
+			return
+		}
+
+		run.interrupted.Store(true)
+
+		for _, sig := range []os.Signal{os.Interrupt, syscall.SIGTERM} {
+			if run.signalClients(sig) == 0 {
+				break
+			}
+
+			select {
+			case <-time.After(interruptGracePeriod):
+			case <-sigCh:
+			case <-done:
+				return
+			}
+		}
+
+		run.CleanupClients()
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+// signalClients sends sig to all running sessions and returns the number of sessions signaled.
+func (run *Run) signalClients(sig os.Signal) int {
+	var n int
+
+	for _, c := range run.RemoteClients {
+		switch remote := c.(type) {
+		case *SSHClient:
+			for i := range remote.Sessions {
+				if remote.Sessions[i].running.Load() && remote.Signal(i, sig) == nil {
+					n += 1
+				}
+			}
+		case *ContainerClient:
+			for i := range remote.Sessions {
+				if remote.Sessions[i].running.Load() && remote.Signal(i, sig) == nil {
+					n += 1
+				}
+			}
+		}
+	}
+
+	for _, c := range run.LocalClients {
+		if local, ok := c.(*LocalhostClient); ok {
+			for i := range local.Sessions {
+				if local.Sessions[i].running.Load() && local.Signal(i, sig) == nil {
+					n += 1
+				}
+			}
+		}
+	}
+
+	return n
+}
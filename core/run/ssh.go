@@ -10,6 +10,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -38,6 +39,7 @@ type SSHClient struct {
 
 	connString string
 	connOpened bool
+	closeMu    sync.Mutex // Close is called by workers and the interrupt handler
 
 	Sessions []SSHSession
 }
@@ -48,7 +50,7 @@ type SSHSession struct {
 	remoteStdout io.Reader
 	remoteStderr io.Reader
 	sessOpened   bool
-	running      bool
+	running      atomic.Bool
 }
 
 type Identity struct {
@@ -149,6 +151,94 @@ func (c *SSHClient) Run(i int, env []string, workDir string, shell string, cmdSt
 		return err
 	}
 
+	cmdString := remoteCmdString(env, workDir, shell, cmdStr)
+
+	// Start the remote command.
+	if err := sess.Start(cmdString); err != nil {
+		return err
+	}
+
+	c.Sessions[i].sess = sess
+	c.Sessions[i].sessOpened = true
+	c.Sessions[i].running.Store(true)
+
+	return nil
+}
+
+// RunPTY runs a command remotely on c.host inside a pseudo-terminal of the given size.
+// Stdout and stderr are merged by the remote terminal, so only Stdout carries output.
+func (c *SSHClient) RunPTY(
+	i int,
+	env []string,
+	workDir string,
+	shell string,
+	cmdStr string,
+	termType string,
+	height int,
+	width int,
+) error {
+	sess, err := c.conn.NewSession()
+	if err != nil {
+		return err
+	}
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+
+	if err := sess.RequestPty(termType, height, width, modes); err != nil {
+		_ = sess.Close()
+		return err
+	}
+
+	c.Sessions[i].remoteStdin, err = sess.StdinPipe()
+	if err != nil {
+		_ = sess.Close()
+		return err
+	}
+
+	c.Sessions[i].remoteStdout, err = sess.StdoutPipe()
+	if err != nil {
+		_ = sess.Close()
+		return err
+	}
+
+	c.Sessions[i].remoteStderr, err = sess.StderrPipe()
+	if err != nil {
+		_ = sess.Close()
+		return err
+	}
+
+	cmdString := remoteCmdString(env, workDir, shell, cmdStr)
+
+	// Start the remote command.
+	if err := sess.Start(cmdString); err != nil {
+		_ = sess.Close()
+		return err
+	}
+
+	c.Sessions[i].sess = sess
+	c.Sessions[i].sessOpened = true
+	c.Sessions[i].running.Store(true)
+
+	return nil
+}
+
+// WindowChange informs the remote pseudo-terminal that the local terminal has been resized.
+func (c *SSHClient) WindowChange(i int, height int, width int) error {
+	if !c.Sessions[i].sessOpened {
+		return fmt.Errorf("session is not open")
+	}
+
+	return c.Sessions[i].sess.WindowChange(height, width)
+}
+
+// remoteCmdString exports the envs, changes to the work dir and wraps the command in
+// the shell, for instance:
+// cd /tmp && export FOO='bar'; bash -c 'echo $FOO'
+func remoteCmdString(env []string, workDir string, shell string, cmdStr string) string {
 	exportedEnv := AsExport(env)
 
 	var cmdString string
@@ -164,28 +254,19 @@ func (c *SSHClient) Run(i int, env []string, workDir string, shell string, cmdSt
 		cmdString = fmt.Sprintf("%s %s", cmdString, cmdStr)
 	}
 
-	// Start the remote command.
-	if err := sess.Start(cmdString); err != nil {
-		return err
-	}
-
-	c.Sessions[i].sess = sess
-	c.Sessions[i].sessOpened = true
-	c.Sessions[i].running = true
-
-	return nil
+	return cmdString
 }
 
 // Wait waits until the remote command finishes and exits.
 // It closes the SSH session.
 func (c *SSHClient) Wait(i int) error {
-	if !c.Sessions[i].running {
+	if !c.Sessions[i].running.Load() {
 		return fmt.Errorf("trying to wait on stopped session")
 	}
 
 	err := c.Sessions[i].sess.Wait()
 	_ = c.Sessions[i].sess.Close()
-	c.Sessions[i].running = false
+	c.Sessions[i].running.Store(false)
 	c.Sessions[i].sessOpened = false
 
 	return err
@@ -193,6 +274,9 @@ func (c *SSHClient) Wait(i int) error {
 
 // Close closes the underlying SSH connection and session.
 func (c *SSHClient) Close(i int) error {
+	c.closeMu.Lock()
+	defer c.closeMu.Unlock()
+
 	if c.Sessions[i].sessOpened {
 		_ = c.Sessions[i].sess.Close()
 		c.Sessions[i].sessOpened = false
@@ -203,7 +287,7 @@ func (c *SSHClient) Close(i int) error {
 
 	err := c.conn.Close()
 	c.connOpened = false
-	c.Sessions[i].running = false
+	c.Sessions[i].running.Store(false)
 
 	return err
 }
@@ -253,6 +337,8 @@ func (c *SSHClient) Signal(i int, sig os.Signal) error {
 	switch sig {
 	case os.Interrupt:
 		return c.Sessions[i].sess.Signal(ssh.SIGINT)
+	case syscall.SIGTERM:
+		return c.Sessions[i].sess.Signal(ssh.SIGTERM)
 	default:
 		return fmt.Errorf("%v not supported", sig)
 	}
@@ -2,6 +2,7 @@ package run
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -82,6 +83,8 @@ func (run *Run) Table(dryRun bool) (dao.TableOutput, dao.ReportData, error) {
 		err = run.linear(data, reportData, dryRun)
 	}
 
+	splitLoopColumns(task.Tasks, &data, &reportData)
+
 	reportData.Status = make(map[dao.TaskStatus]int, 5)
 	for i := range reportData.Tasks {
 		reportData.Tasks[i].Status = make(map[dao.TaskStatus]int, 5)
@@ -468,6 +471,11 @@ func (run *Run) tableWork(
 	reportData dao.ReportData,
 	dryRun bool,
 ) error {
+	// Don't start new commands once the run has been interrupted
+	if run.interrupted.Load() {
+		return errInterrupted
+	}
+
 	var wg sync.WaitGroup
 
 	var registerEnvs []string
@@ -483,8 +491,12 @@ func (run *Run) tableWork(
 		client = run.RemoteClients[r.Server.Name]
 	}
 
-	shell := dao.SelectFirstNonEmpty((*r.Cmd).Shell, r.Task.Shell, r.Server.Shell, run.Config.Shell)
-	shell = core.FormatShell(shell)
+	cmdRuns, err := run.expandCmd(r, register)
+	if err != nil {
+		return err
+	}
+
+	shell := getShell(r.Cmd, r.Task, r.Server, run.Config.Shell)
 	workDir := getWorkDir((*r.Cmd).Local, (*r.Server).Local, (*r.Cmd).WorkDir, (*r.Server).WorkDir, (*r.Cmd).RootDir, (*r.Server).RootDir)
 	t := TaskContext{
 		rIndex:  r.i,
@@ -494,12 +506,30 @@ func (run *Run) tableWork(
 		env:     combinedEnvs,
 		workDir: workDir,
 		shell:   shell,
-		cmd:     r.Cmd.Cmd,
 		tty:     r.Cmd.TTY,
+		pty:     r.Cmd.PTY,
+		stdin:   run.stdin,
+
+		localEnvClear: r.Cmd.LocalEnvClear,
 	}
 
 	start := time.Now()
-	out, stdout, stderr, err := runTableCmd(si, t, &wg)
+	var out, stdout, stderr string
+	for _, c := range cmdRuns {
+		t.cmd = c.cmd
+		t.env = dao.MergeEnvs(c.envs, combinedEnvs)
+
+		cmdStart := time.Now()
+		o, so, se, e := runTableCmd(si, t, &wg)
+		out, stdout, stderr, err = out+o, stdout+so, stderr+se, e
+		if r.Cmd.Loop != nil {
+			report := &reportData.Tasks[r.i].Rows[r.j]
+			report.Loop = append(report.Loop, run.loopReport(r, c, cmdStart, tableCell(r.Task.Spec.Print, o, so, se, e), e))
+		}
+		if err != nil {
+			break
+		}
+	}
 	reportData.Tasks[r.i].Rows[r.j].Duration = time.Since(start)
 
 	var errCode int
@@ -514,25 +544,7 @@ func (run *Run) tableWork(
 	// dataMutex.Lock()
 	// out, err := io.ReadAll(client.Stderr())
 	// dataMutex.Unlock()
-	if err != nil {
-		switch r.Task.Spec.Print {
-		case "stdout":
-			data.Rows[t.rIndex].Columns[t.cIndex] = stdout
-		case "stderr":
-			data.Rows[t.rIndex].Columns[t.cIndex] = fmt.Sprintf("%s\n%s", stderr, err.Error())
-		default:
-			data.Rows[t.rIndex].Columns[t.cIndex] = fmt.Sprintf("%s\n%s", out, err.Error())
-		}
-	} else {
-		switch r.Task.Spec.Print {
-		case "stdout":
-			data.Rows[t.rIndex].Columns[t.cIndex] = stdout
-		case "stderr":
-			data.Rows[t.rIndex].Columns[t.cIndex] = stderr
-		default:
-			data.Rows[t.rIndex].Columns[t.cIndex] = strings.TrimSuffix(out, "\n")
-		}
-	}
+	data.Rows[t.rIndex].Columns[t.cIndex] = tableCell(r.Task.Spec.Print, out, stdout, stderr, err)
 
 	reportData.Tasks[r.i].Rows[r.j].ReturnCode = errCode
 
@@ -556,6 +568,14 @@ func (run *Run) tableWork(
 	}
 
 	if err != nil {
+		if errors.Is(err, errInterrupted) {
+			run.interrupted.Store(true)
+		}
+		if run.interrupted.Load() {
+			reportData.Tasks[r.i].Rows[r.j].Status = dao.Interrupted
+			return err
+		}
+
 		if r.Task.Spec.IgnoreErrors || r.Cmd.IgnoreErrors {
 			reportData.Tasks[r.i].Rows[r.j].Status = dao.Ignored
 			return nil
@@ -570,29 +590,64 @@ func (run *Run) tableWork(
 	return nil
 }
 
+// tableCell returns the table output of a command that ran with the error err.
+func tableCell(print string, out string, stdout string, stderr string, err error) string {
+	if err != nil {
+		switch print {
+		case "stdout":
+			return stdout
+		case "stderr":
+			return fmt.Sprintf("%s\n%s", stderr, err.Error())
+		default:
+			return fmt.Sprintf("%s\n%s", out, err.Error())
+		}
+	}
+
+	switch print {
+	case "stdout":
+		return stdout
+	case "stderr":
+		return stderr
+	default:
+		return strings.TrimSuffix(out, "\n")
+	}
+}
+
 func runTableCmd(i int, t TaskContext, wg *sync.WaitGroup) (string, string, string, error) {
 	buf := new(bytes.Buffer)
 	bufOut := new(bytes.Buffer)
 	bufErr := new(bytes.Buffer)
 
 	if t.dryRun {
-		return t.cmd, bufOut.String(), bufErr.String(), nil
+		return core.MaskSecrets(t.cmd), bufOut.String(), bufErr.String(), nil
 	}
 
 	if t.tty {
 		return buf.String(), bufOut.String(), bufErr.String(), ExecTTY(t.cmd, t.env)
 	}
 
+	if remote, ok := t.client.(*SSHClient); ok && t.pty {
+		return runPTYCmd(i, t, remote)
+	}
+
+	if local, ok := t.client.(*LocalhostClient); ok {
+		local.SetEnvClear(i, t.localEnvClear)
+	}
+
 	err := t.client.Run(i, t.env, t.workDir, t.shell, t.cmd)
 	if err != nil {
 		return buf.String(), bufOut.String(), bufErr.String(), err
 	}
 
+	if t.stdin != nil {
+		go writeStdinInput(i, t.client, t.stdin)
+	}
+
 	// Copy over commands STDOUT.
 	var stdoutHandler = func(i int, client Client) {
 		defer wg.Done()
 		mw := io.MultiWriter(buf, bufOut)
-		_, err = io.Copy(mw, client.Stdout(i))
+		_, err = io.Copy(mw, core.NewMaskReader(client.Stdout(i)))
 
 		if err != nil && err != io.EOF {
 			fmt.Fprintf(os.Stderr, "%v", err)
@@ -605,7 +660,7 @@ func runTableCmd(i int, t TaskContext, wg *sync.WaitGroup) (string, string, stri
 	var stderrHandler = func(i int, client Client) {
 		defer wg.Done()
 		mw := io.MultiWriter(buf, bufErr)
-		_, err = io.Copy(mw, client.Stderr(i))
+		_, err = io.Copy(mw, core.NewMaskReader(client.Stderr(i)))
 		if err != nil && err != io.EOF {
 			fmt.Fprintf(os.Stderr, "%v", err)
 		}
@@ -3,6 +3,7 @@ package run
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -66,6 +67,8 @@ func (run *Run) Text(dryRun bool) (dao.ReportData, error) {
 		err = run.linearText(prefixMaxLen, reportData, dryRun)
 	}
 
+	splitLoopColumns(task.Tasks, nil, &reportData)
+
 	reportData.Status = make(map[dao.TaskStatus]int, 5)
 	for i := range reportData.Tasks {
 		reportData.Tasks[i].Status = make(map[dao.TaskStatus]int, 5)
@@ -483,6 +486,11 @@ func (run *Run) textWork(
 	dryRun bool,
 	batch int,
 ) error {
+	// Don't start new commands once the run has been interrupted
+	if run.interrupted.Load() {
+		return errInterrupted
+	}
+
 	numTasks := len(r.Task.Tasks)
 
 	var registerEnvs []string
@@ -503,8 +511,12 @@ func (run *Run) textWork(
 		return err
 	}
 
-	shell := dao.SelectFirstNonEmpty((*r.Cmd).Shell, r.Task.Shell, r.Server.Shell, run.Config.Shell)
-	shell = core.FormatShell(shell)
+	cmdRuns, err := run.expandCmd(r, register)
+	if err != nil {
+		return err
+	}
+
+	shell := getShell(r.Cmd, r.Task, r.Server, run.Config.Shell)
 	workDir := getWorkDir((*r.Cmd).Local, (*r.Server).Local, (*r.Cmd).WorkDir, (*r.Server).WorkDir, (*r.Cmd).RootDir, (*r.Server).RootDir)
 	t := TaskContext{
 		rIndex:   r.i,
@@ -514,17 +526,35 @@ func (run *Run) textWork(
 		env:      combinedEnvs,
 		workDir:  workDir,
 		shell:    shell,
-		cmd:      r.Cmd.Cmd,
 		desc:     r.Cmd.Desc,
 		name:     r.Cmd.Name,
 		numTasks: numTasks,
 		tty:      r.Cmd.TTY,
-		print:    r.Task.Spec.Print,
+		pty:      r.Cmd.PTY,
+		stdin:    run.stdin,
+
+		localEnvClear: r.Cmd.LocalEnvClear,
+		print:         r.Task.Spec.Print,
 	}
 
 	start := time.Now()
 	var wg sync.WaitGroup
-	out, stdout, stderr, err := runTextCmd(si, t, prefix, r.Cmd.Register, &wg)
+	var out, stdout, stderr string
+	for _, c := range cmdRuns {
+		t.cmd = c.cmd
+		t.env = dao.MergeEnvs(c.envs, combinedEnvs)
+
+		cmdStart := time.Now()
+		o, so, se, e := runTextCmd(si, t, prefix, r.Cmd.Register, &wg)
+		out, stdout, stderr, err = out+o, stdout+so, stderr+se, e
+		if r.Cmd.Loop != nil {
+			report := &reportData.Tasks[r.i].Rows[r.j]
+			report.Loop = append(report.Loop, run.loopReport(r, c, cmdStart, "", e))
+		}
+		if err != nil {
+			break
+		}
+	}
 	reportData.Tasks[r.i].Rows[r.j].Duration = time.Since(start)
 
 	// Add exit code to reportData
@@ -562,6 +592,14 @@ func (run *Run) textWork(
 	}
 
 	if err != nil {
+		if errors.Is(err, errInterrupted) {
+			run.interrupted.Store(true)
+		}
+		if run.interrupted.Load() {
+			reportData.Tasks[r.i].Rows[r.j].Status = dao.Interrupted
+			return err
+		}
+
 		if r.Task.Spec.IgnoreErrors || r.Cmd.IgnoreErrors {
 			reportData.Tasks[r.i].Rows[r.j].Status = dao.Ignored
 			return nil
@@ -596,11 +634,23 @@ func runTextCmd(
 		return buf.String(), bufOut.String(), bufErr.String(), ExecTTY(t.cmd, t.env)
 	}
 
+	if remote, ok := t.client.(*SSHClient); ok && t.pty {
+		return runPTYCmd(i, t, remote)
+	}
+
+	if local, ok := t.client.(*LocalhostClient); ok {
+		local.SetEnvClear(i, t.localEnvClear)
+	}
+
 	err := t.client.Run(i, t.env, t.workDir, t.shell, t.cmd)
 	if err != nil {
 		return buf.String(), bufOut.String(), bufErr.String(), err
 	}
 
+	if t.stdin != nil {
+		go writeStdinInput(i, t.client, t.stdin)
+	}
+
 	// Copy over commands STDOUT.
 	go func(client Client) {
 		defer wg.Done()
@@ -609,15 +659,15 @@ func runTextCmd(
 		if register == "" {
 			if t.print != "stderr" {
 				if prefix != "" {
-					_, err = io.Copy(os.Stdout, core.NewPrefixer(client.Stdout(i), prefix))
+					_, err = io.Copy(os.Stdout, core.NewPrefixer(core.NewMaskReader(client.Stdout(i)), prefix))
 				} else {
-					_, err = io.Copy(os.Stdout, client.Stdout(i))
+					_, err = io.Copy(os.Stdout, core.NewMaskReader(client.Stdout(i)))
 				}
 			}
 		} else {
 			if t.print != "stderr" {
 				mw := io.MultiWriter(buf, bufOut)
-				r := io.TeeReader(client.Stdout(i), mw)
+				r := io.TeeReader(core.NewMaskReader(client.Stdout(i)), mw)
 				// TODO: Refactor to NewReader: https://pkg.go.dev/golang.org/x/text/transform?utm_source=godoc#NewReader
 				if prefix != "" {
 					_, err = io.Copy(os.Stdout, core.NewPrefixer(r, prefix))
@@ -626,7 +676,7 @@ func runTextCmd(
 				}
 			} else { // don't write to stdout
 				mw := io.MultiWriter(buf, bufOut)
-				r := io.TeeReader(client.Stdout(i), mw)
+				r := io.TeeReader(core.NewMaskReader(client.Stdout(i)), mw)
 				// TODO: Refactor to NewReader: https://pkg.go.dev/golang.org/x/text/transform?utm_source=godoc#NewReader
 				_, err = io.Copy(mw, r)
 			}
@@ -646,15 +696,15 @@ func runTextCmd(
 		if register == "" {
 			if t.print != "stdout" {
 				if prefix != "" {
-					_, err = io.Copy(os.Stderr, core.NewPrefixer(client.Stderr(i), prefix))
+					_, err = io.Copy(os.Stderr, core.NewPrefixer(core.NewMaskReader(client.Stderr(i)), prefix))
 				} else {
-					_, err = io.Copy(os.Stderr, client.Stderr(i))
+					_, err = io.Copy(os.Stderr, core.NewMaskReader(client.Stderr(i)))
 				}
 			}
 		} else {
 			if t.print != "stdout" {
 				mw := io.MultiWriter(buf, bufErr)
-				r := io.TeeReader(client.Stderr(i), mw)
+				r := io.TeeReader(core.NewMaskReader(client.Stderr(i)), mw)
 				// TODO: Refactor to NewReader: https://pkg.go.dev/golang.org/x/text/transform?utm_source=godoc#NewReader
 				if prefix != "" {
 					_, err = io.Copy(os.Stderr, core.NewPrefixer(r, prefix))
@@ -663,7 +713,7 @@ func runTextCmd(
 				}
 			} else { // don't write to stdout
 				mw := io.MultiWriter(buf, bufErr)
-				r := io.TeeReader(client.Stderr(i), mw)
+				r := io.TeeReader(core.NewMaskReader(client.Stderr(i)), mw)
 				// TODO: Refactor to NewReader: https://pkg.go.dev/golang.org/x/text/transform?utm_source=godoc#NewReader
 				_, err = io.Copy(mw, r)
 			}
@@ -911,7 +961,7 @@ func calcMaxPrefixLength(clients map[string]Client, task dao.Task) (int, error)
 }
 
 func printCmd(prefix string, cmd string) {
-	scanner := bufio.NewScanner(strings.NewReader(cmd))
+	scanner := bufio.NewScanner(strings.NewReader(core.MaskSecrets(cmd)))
 	for scanner.Scan() {
 		fmt.Printf("%s%s\n", prefix, scanner.Text())
 	}
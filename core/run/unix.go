@@ -5,16 +5,24 @@ package run
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/alajmo/sake/core/dao"
 	"golang.org/x/sys/unix"
+	"golang.org/x/term"
 )
 
 // func SSHToServer(host string, user string, port uint16, bastion string, disableVerifyHost bool, knownHostFile string) error {
 func SSHToServer(server dao.Server, disableVerifyHost bool, knownHostFile string) error {
+	if server.Connection != dao.ConnectionSSH {
+		return ExecToContainer(server)
+	}
+
 	sshBin, err := exec.LookPath("ssh")
 	if err != nil {
 		return err
@@ -52,6 +60,32 @@ func SSHToServer(server dao.Server, disableVerifyHost bool, knownHostFile string
 	return nil
 }
 
+// ExecToContainer replaces the current process with an interactive shell in the container or pod.
+func ExecToContainer(server dao.Server) error {
+	bin, err := exec.LookPath(server.Connection)
+	if err != nil {
+		return err
+	}
+
+	client := ContainerClient{
+		User:       server.User,
+		Host:       server.Host,
+		Connection: server.Connection,
+		Container:  server.Container,
+		Namespace:  server.Namespace,
+	}
+
+	shell := server.Shell
+	if shell == "" {
+		shell = "sh"
+	}
+
+	args := append([]string{server.Connection}, client.execArgs(true)...)
+	args = append(args, shell)
+
+	return unix.Exec(bin, args, os.Environ())
+}
+
 func ExecTTY(cmd string, envs []string) error {
 	shell := "bash"
 	foundShell, found := os.LookupEnv("SHELL")
@@ -72,3 +106,131 @@ func ExecTTY(cmd string, envs []string) error {
 
 	return nil
 }
+
+// watchWindowSize calls onChange with the new terminal size whenever the terminal
+// is resized. The returned function stops watching.
+func watchWindowSize(fd int, onChange func(width int, height int)) func() {
+	sigCh := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigCh, syscall.SIGWINCH)
+
+	go func() {
+		for {
+			select {
+			case <-sigCh:
+				width, height, err := term.GetSize(fd)
+				if err == nil {
+					onChange(width, height)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigCh)
+		close(done)
+	}
+}
+
+var minimalEnvKeys = []string{"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "LANG", "TMPDIR"}
+
+// forwardInput writes input read from f to w until the returned function is called or the input
+// ends, in which case w is closed to send EOF to the remote command. f is only read once input is
+// available, so no input is consumed after forwarding stops and later prompts still receive it.
+func forwardInput(f *os.File, w io.WriteCloser) (func(), error) {
+	wakeR, wakeW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	fd, wakeFd := int(f.Fd()), int(wakeR.Fd())
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		defer wakeR.Close()
+
+		b := make([]byte, 1024)
+		for {
+			// select rather than poll, since poll doesn't support terminals on macOS
+			fds := &unix.FdSet{}
+			fds.Set(fd)
+			fds.Set(wakeFd)
+			_, err := unix.Select(max(fd, wakeFd)+1, fds, nil, nil, nil)
+			if err == unix.EINTR {
+				continue
+			}
+			if err != nil || fds.IsSet(wakeFd) {
+				return
+			}
+
+			n, err := f.Read(b)
+			if n > 0 {
+				_, _ = w.Write(b[:n])
+			}
+			if err != nil {
+				_ = w.Close()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		_ = wakeW.Close()
+		<-stopped
+	}, nil
+}
+
+// stdinFd is checked to decide whether local commands take over the terminal.
+var stdinFd = int(os.Stdin.Fd())
+
+// setProcessGroup starts the command in a new process group, so that signals also reach processes
+// started by the command. If stdin is the terminal and sake runs in the foreground, the group is
+// put in the foreground, so that commands can still read from the terminal, for instance sudo
+// password prompts, and receive Ctrl-C directly. See releaseForeground.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	pgrp, err := unix.IoctlGetInt(stdinFd, unix.TIOCGPGRP)
+	if err == nil && pgrp == syscall.Getpgrp() {
+		cmd.SysProcAttr.Foreground = true
+		cmd.SysProcAttr.Ctty = stdinFd
+	}
+}
+
+// releaseForeground gives the terminal back to sake once a command that was put in the
+// foreground exits, and reports whether the command was interrupted by Ctrl-C. Ctrl-C only
+// reached the command, so the interrupt is raised in sake as well, to stop the other sessions.
+func releaseForeground(cmd *exec.Cmd) bool {
+	if cmd.SysProcAttr == nil || !cmd.SysProcAttr.Foreground {
+		return false
+	}
+
+	// sake is in the background until the terminal is given back, so ignore SIGTTOU meanwhile
+	signal.Ignore(syscall.SIGTTOU)
+	_ = unix.IoctlSetPointerInt(stdinFd, unix.TIOCSPGRP, syscall.Getpgrp())
+	signal.Reset(syscall.SIGTTOU)
+
+	if cmd.ProcessState == nil {
+		return false
+	}
+	status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() || status.Signal() != syscall.SIGINT {
+		return false
+	}
+
+	_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+	return true
+}
+
+// signalProcessGroup sends sig to all processes in the process group of the command.
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok || cmd.SysProcAttr == nil || !cmd.SysProcAttr.Setpgid {
+		return cmd.Process.Signal(sig)
+	}
+
+	return syscall.Kill(-cmd.Process.Pid, s)
+}
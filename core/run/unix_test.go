@@ -0,0 +1,161 @@
+//go:build !windows
+// +build !windows
+
+package run
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLocalProcessGroup(t *testing.T) {
+	fd := stdinFd
+	t.Cleanup(func() { stdinFd = fd })
+
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	defer devNull.Close()
+
+	// A terminal that is not the controlling terminal of sake is not taken over
+	stdins := []int{int(devNull.Fd())}
+	if ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0); err == nil {
+		defer ptmx.Close()
+		stdins = append(stdins, int(ptmx.Fd()))
+	}
+
+	for _, stdin := range stdins {
+		stdinFd = stdin
+
+		client := LocalhostClient{Sessions: make([]LocalSession, 1)}
+		if err := client.Run(0, []string{}, "", "sh -c", "sleep 30 & echo $!; wait"); err != nil {
+			t.Fatalf("%q", err)
+		}
+		pid := client.Sessions[0].cmd.Process.Pid
+		pgid, err := syscall.Getpgid(pid)
+		if err != nil {
+			t.Fatalf("%q", err)
+		}
+		if pgid != pid {
+			t.Fatalf("Wanted: process group %d, Found: %d", pid, pgid)
+		}
+
+		line, err := bufio.NewReader(client.Stdout(0)).ReadString('\n')
+		if err != nil {
+			t.Fatalf("%q", err)
+		}
+		sleepPid, err := strconv.Atoi(strings.TrimSpace(line))
+		if err != nil {
+			t.Fatalf("%q", err)
+		}
+
+		// Processes started by the command are killed as well
+		_ = client.Close(0)
+		_ = client.Wait(0)
+		for range 100 {
+			if !processAlive(sleepPid) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if processAlive(sleepPid) {
+			t.Fatalf("Wanted: background process %d to be killed", sleepPid)
+		}
+	}
+}
+
+// processAlive reports whether the process exists and is not a zombie.
+func processAlive(pid int) bool {
+	if stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid)); err == nil {
+		_, fields, _ := strings.Cut(string(stat), ") ")
+		return !strings.HasPrefix(fields, "Z")
+	}
+
+	return syscall.Kill(pid, 0) == nil
+}
+
+type inputBuffer struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (b *inputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *inputBuffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+func (b *inputBuffer) wait(t *testing.T, want string, closed bool) {
+	t.Helper()
+	for range 100 {
+		b.mu.Lock()
+		got, isClosed := b.buf.String(), b.closed
+		b.mu.Unlock()
+		if got == want && isClosed == closed {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Wanted: %q (closed %t), Found: %q", want, closed, b.buf.String())
+}
+
+func TestForwardInput(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	defer r.Close()
+
+	first := &inputBuffer{}
+	stop, err := forwardInput(r, first)
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	_, _ = w.Write([]byte("ls\n"))
+	first.wait(t, "ls\n", false)
+	stop()
+
+	// Input after the session stops is left for later readers, such as prompts
+	_, _ = w.Write([]byte("yes\n"))
+	time.Sleep(50 * time.Millisecond)
+	first.wait(t, "ls\n", false)
+
+	b := make([]byte, 16)
+	n, err := r.Read(b)
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	if string(b[:n]) != "yes\n" {
+		t.Fatalf("Wanted: %q, Found: %q", "yes\n", b[:n])
+	}
+
+	second := &inputBuffer{}
+	stop, err = forwardInput(r, second)
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	_, _ = w.Write([]byte("exit\n"))
+	second.wait(t, "exit\n", false)
+
+	// End of input closes the remote stdin
+	_ = w.Close()
+	second.wait(t, "exit\n", true)
+	stop()
+}
@@ -4,6 +4,10 @@
 package run
 
 import (
+	"io"
+	"os"
+	"os/exec"
+
 	"github.com/alajmo/sake/core/dao"
 )
 
@@ -14,3 +18,47 @@ func SSHToServer(server dao.Server, disableVerifyHost bool, knownHostFile string
 func ExecTTY(cmd string, envs []string) error {
 	return nil
 }
+
+func watchWindowSize(fd int, onChange func(width int, height int)) func() {
+	return func() {}
+}
+
+// forwardInput writes input read from f to w until the returned function is called or the input
+// ends, in which case w is closed to send EOF to the remote command. Reads can't be interrupted
+// here, so the read pending when forwarding stops consumes the next chunk of input.
+func forwardInput(f *os.File, w io.WriteCloser) (func(), error) {
+	done := make(chan struct{})
+
+	go func() {
+		b := make([]byte, 1024)
+		for {
+			n, err := f.Read(b)
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if n > 0 {
+				_, _ = w.Write(b[:n])
+			}
+			if err != nil {
+				_ = w.Close()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }, nil
+}
+
+var minimalEnvKeys = []string{"PATH", "PATHEXT", "SYSTEMROOT", "COMSPEC", "TEMP", "TMP", "USERPROFILE"}
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func releaseForeground(cmd *exec.Cmd) bool {
+	return false
+}
+
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	return cmd.Process.Signal(sig)
+}
@@ -35,6 +35,27 @@ func ParseSSHConfig(path string) (map[string](Endpoint), error) {
 	return hosts, nil
 }
 
+// ParseSSHConfigHosts returns the hosts of the config in the order they are defined.
+func ParseSSHConfigHosts(path string) ([]Endpoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	endpoints, err := ParseReader(f, path)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := []Endpoint{}
+	for _, e := range endpoints {
+		hosts = append(hosts, *e)
+	}
+
+	return hosts, nil
+}
+
 // ParseReader reads and parses the given reader.
 func ParseReader(r io.Reader, cfg string) ([]*Endpoint, error) {
 	infos, err := parseInternal(r, cfg)
@@ -80,6 +101,7 @@ func ParseReader(r io.Reader, cfg string) ([]*Endpoint, error) {
 			RemoteCommand: info.RemoteCommand,
 			SetEnv:        info.SetEnv,
 			SendEnv:       info.SendEnv,
+			Tags:          info.Tags,
 		})
 		return nil
 	}); err != nil {
@@ -110,6 +132,7 @@ type Endpoint struct {
 	SendEnv       []string
 	SetEnv        []string
 	IdentityFiles []string
+	Tags          []string // from comments, # tags: web, prod
 }
 
 type hostinfo struct {
@@ -123,6 +146,7 @@ type hostinfo struct {
 	SendEnv       []string
 	SetEnv        []string
 	IdentityFiles []string
+	Tags          []string
 }
 
 type hostinfoMap struct {
@@ -205,6 +229,15 @@ func parseInternal(r io.Reader, cfg string) (*hostinfoMap, error) {
 				}
 
 				if strings.HasPrefix(node, "#") {
+					// Tags used by sake, # tags: web, prod
+					comment := strings.TrimSpace(strings.TrimPrefix(node, "#"))
+					if tags, found := strings.CutPrefix(comment, "tags:"); found {
+						for _, tag := range strings.Split(tags, ",") {
+							if tag = strings.TrimSpace(tag); tag != "" {
+								info.Tags = append(info.Tags, tag)
+							}
+						}
+					}
 					continue
 				}
 
@@ -327,6 +360,7 @@ func mergeHostinfo(h1 hostinfo, h2 hostinfo) hostinfo {
 	}
 	h2.SendEnv = append(h2.SendEnv, h1.SendEnv...)
 	h2.SetEnv = append(h2.SetEnv, h1.SetEnv...)
+	h2.Tags = append(append([]string{}, h1.Tags...), h2.Tags...)
 
 	return h2
 }
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Vault files are AES-256-GCM encrypted with a key derived from a passphrase via scrypt.
+// The file consists of a header line followed by the base64 encoded salt, nonce and ciphertext.
+const (
+	VaultHeader = "$SAKE_VAULT;1;AES256"
+
+	vaultSaltSize  = 16
+	vaultLineWidth = 80
+)
+
+var ErrVaultDecrypt = errors.New("failed to decrypt vault, wrong passphrase or corrupted file")
+
+func IsVault(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(VaultHeader))
+}
+
+func EncryptVault(plaintext []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, vaultSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := vaultCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	data := append(salt, nonce...)
+	data = gcm.Seal(data, nonce, plaintext, []byte(VaultHeader))
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	var out strings.Builder
+	out.WriteString(VaultHeader + "\n")
+	for len(encoded) > 0 {
+		n := min(vaultLineWidth, len(encoded))
+		out.WriteString(encoded[:n] + "\n")
+		encoded = encoded[n:]
+	}
+
+	return []byte(out.String()), nil
+}
+
+func DecryptVault(vault []byte, passphrase string) ([]byte, error) {
+	header, body, _ := bytes.Cut(vault, []byte("\n"))
+	if string(bytes.TrimSpace(header)) != VaultHeader {
+		return nil, errors.New("not a sake vault file")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(body)), ""))
+	if err != nil {
+		return nil, ErrVaultDecrypt
+	}
+
+	if len(data) < vaultSaltSize {
+		return nil, ErrVaultDecrypt
+	}
+
+	gcm, err := vaultCipher(passphrase, data[:vaultSaltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[vaultSaltSize:]
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrVaultDecrypt
+	}
+
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(VaultHeader))
+	if err != nil {
+		return nil, ErrVaultDecrypt
+	}
+
+	return plaintext, nil
+}
+
+func vaultCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,30 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestVault(t *testing.T) {
+	plaintext := "db_password: hunter2\n"
+
+	vault, err := EncryptVault([]byte(plaintext), "passphrase")
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+
+	if !IsVault(vault) {
+		t.Fatalf("Wanted: vault header, Found: %q", vault)
+	}
+
+	data, err := DecryptVault(vault, "passphrase")
+	if err != nil {
+		t.Fatalf("%q", err)
+	}
+	if string(data) != plaintext {
+		t.Fatalf(`Wanted: %q, Found: %q`, plaintext, data)
+	}
+
+	if _, err := DecryptVault(vault, "wrong"); err != ErrVaultDecrypt {
+		t.Fatalf(`Wanted: %q, Found: %q`, ErrVaultDecrypt, err)
+	}
+}